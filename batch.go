@@ -0,0 +1,177 @@
+package changecase
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Manifest formats accepted by ReadManifest
+const (
+	ManifestAuto   = "auto"   // chosen by DetectManifestFormat
+	ManifestTSV    = "tsv"    // expected<TAB>actual[<TAB>name] per line
+	ManifestNDJSON = "ndjson" // {"expected": ..., "actual": ..., "name": ...} per line
+	ManifestPairs  = "pairs"  // every two consecutive lines make up a pair
+)
+
+// BatchPair - one expected/actual pair read from a manifest
+type BatchPair struct {
+	Name     string // the name given in the manifest, or where the pair is in it
+	Expected string
+	Actual   string
+}
+
+// ReadManifest - read the pairs of a manifest, decoded as by DecodeReader.
+// With ManifestAuto, the format is detected from source, the name of the
+// manifest, and its contents.
+func ReadManifest(r io.Reader, source, format string) ([]BatchPair, error) {
+	decoded, _, err := DecodeReader(r)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := readManifestLines(decoded)
+	if err != nil {
+		return nil, err
+	}
+	if format == ManifestAuto {
+		format = DetectManifestFormat(source, lines)
+	}
+	return ParseManifest(lines, format)
+}
+
+// readManifestLines - read every line of r without a length limit, dropping
+// the line endings
+func readManifestLines(r io.Reader) ([]string, error) {
+	var lines []string
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			lines = append(lines, strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"))
+		}
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// DetectManifestFormat - pick the manifest format from the file extension of
+// source, or else from the first non-empty line: NDJSON when it starts with
+// "{", TSV when it holds a tab, and consecutive line pairs otherwise
+func DetectManifestFormat(source string, lines []string) string {
+	switch strings.ToLower(filepath.Ext(source)) {
+	case ".ndjson", ".jsonl":
+		return ManifestNDJSON
+	case ".tsv":
+		return ManifestTSV
+	}
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "{") {
+			return ManifestNDJSON
+		}
+		if strings.Contains(line, "\t") {
+			return ManifestTSV
+		}
+		break
+	}
+	return ManifestPairs
+}
+
+// unescapeTSV - decode the \t, \n, \r and \\ escapes of a TSV field
+func unescapeTSV(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+	return strings.NewReplacer(`\t`, "\t", `\n`, "\n", `\r`, "\r", `\\`, `\`).Replace(field)
+}
+
+// ParseManifest - convert the lines of a manifest into pairs. TSV lines hold
+// "expected<TAB>actual[<TAB>name]", NDJSON lines hold objects with
+// "expected", "actual" and an optional "name", and in the pairs format every
+// two consecutive lines make up a pair. Blank TSV and NDJSON lines are
+// skipped. Pairs without a name are named after their lines.
+func ParseManifest(lines []string, format string) ([]BatchPair, error) {
+	var pairs []BatchPair
+	switch format {
+	case ManifestPairs:
+		if len(lines)%2 != 0 {
+			return nil, fmt.Errorf("odd number of lines (%d) in a manifest of line pairs", len(lines))
+		}
+		for i := 0; i < len(lines); i += 2 {
+			pairs = append(pairs, BatchPair{fmt.Sprintf("lines %d-%d", i+1, i+2), lines[i], lines[i+1]})
+		}
+	case ManifestTSV:
+		for i, line := range lines {
+			if line == "" {
+				continue
+			}
+			fields := strings.Split(line, "\t")
+			if len(fields) < 2 || len(fields) > 3 {
+				return nil, fmt.Errorf("line %d: expected 2 or 3 tab-separated fields, got %d", i+1, len(fields))
+			}
+			pair := BatchPair{fmt.Sprintf("line %d", i+1), unescapeTSV(fields[0]), unescapeTSV(fields[1])}
+			if len(fields) == 3 && fields[2] != "" {
+				pair.Name = unescapeTSV(fields[2])
+			}
+			pairs = append(pairs, pair)
+		}
+	case ManifestNDJSON:
+		for i, line := range lines {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			var entry struct {
+				Name     string  `json:"name"`
+				Expected *string `json:"expected"`
+				Actual   *string `json:"actual"`
+			}
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				return nil, fmt.Errorf("line %d: %v", i+1, err)
+			}
+			if entry.Expected == nil || entry.Actual == nil {
+				return nil, fmt.Errorf(`line %d: "expected" and "actual" are required`, i+1)
+			}
+			pair := BatchPair{fmt.Sprintf("line %d", i+1), *entry.Expected, *entry.Actual}
+			if entry.Name != "" {
+				pair.Name = entry.Name
+			}
+			pairs = append(pairs, pair)
+		}
+	default:
+		return nil, fmt.Errorf("unknown manifest format %q (want auto, tsv, ndjson or pairs)", format)
+	}
+	return pairs, nil
+}
+
+// CompareBatch - compare the expected and actual strings of every pair using
+// up to workers goroutines, and return the result of each pair in order
+func CompareBatch(pairs []BatchPair, opts Options, workers int) []Result {
+	results := make([]Result, len(pairs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < max(workers, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = Compare(pairs[i].Expected, pairs[i].Actual, opts)
+			}
+		}()
+	}
+	for i := range pairs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
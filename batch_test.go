@@ -0,0 +1,130 @@
+package changecase
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestDetectManifestFormat tests choosing a manifest format from its name
+// and contents
+func TestDetectManifestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		lines    []string
+		expected string
+	}{
+		{"ndjson extension", "cases.ndjson", []string{"a\tb"}, ManifestNDJSON},
+		{"jsonl extension", "CASES.JSONL", nil, ManifestNDJSON},
+		{"tsv extension", "cases.tsv", []string{`{"a": 1}`}, ManifestTSV},
+		{"json object", "-", []string{"", `  {"expected": "a"}`}, ManifestNDJSON},
+		{"tab", "cases.txt", []string{"a\tb"}, ManifestTSV},
+		{"line pairs", "-", []string{"a", "b"}, ManifestPairs},
+		{"empty", "-", nil, ManifestPairs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectManifestFormat(tt.source, tt.lines); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestParseManifest tests reading pairs from each manifest format
+func TestParseManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		format   string
+		expected []BatchPair
+		err      string
+	}{
+		{"pairs", []string{"a", "b", "c", "d"}, ManifestPairs,
+			[]BatchPair{{"lines 1-2", "a", "b"}, {"lines 3-4", "c", "d"}}, ""},
+		{"odd pairs", []string{"a", "b", "c"}, ManifestPairs, nil, "odd number of lines (3)"},
+		{"tsv", []string{"a\tb", "", `x\ty` + "\t" + `z\\`, "c\td\tnamed"}, ManifestTSV,
+			[]BatchPair{{"line 1", "a", "b"}, {"line 3", "x\ty", `z\`}, {"named", "c", "d"}}, ""},
+		{"tsv too few fields", []string{"a"}, ManifestTSV, nil, "line 1: expected 2 or 3 tab-separated fields, got 1"},
+		{"ndjson", []string{`{"expected": "a", "actual": "b"}`, " ", `{"name": "n", "expected": "", "actual": "c"}`}, ManifestNDJSON,
+			[]BatchPair{{"line 1", "a", "b"}, {"n", "", "c"}}, ""},
+		{"ndjson missing field", []string{`{"expected": "a"}`}, ManifestNDJSON, nil, `line 1: "expected" and "actual" are required`},
+		{"ndjson invalid", []string{`{`}, ManifestNDJSON, nil, "line 1: "},
+		{"unknown format", nil, "csv", nil, `unknown manifest format "csv"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseManifest(tt.lines, tt.format)
+			if tt.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+					t.Errorf("Expected an error starting with %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestReadManifest tests reading, decoding and detecting a manifest
+func TestReadManifest(t *testing.T) {
+	input := "\ufeffa\tb\r\nc\td\r\n"
+	pairs, err := ReadManifest(strings.NewReader(input), "-", ManifestAuto)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []BatchPair{{"line 1", "a", "b"}, {"line 2", "c", "d"}}
+	if !reflect.DeepEqual(pairs, expected) {
+		t.Errorf("Expected %q, got %q", expected, pairs)
+	}
+
+	pairs, err = ReadManifest(strings.NewReader(input), "-", ManifestPairs)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected = []BatchPair{{"lines 1-2", "a\tb", "c\td"}}
+	if !reflect.DeepEqual(pairs, expected) {
+		t.Errorf("Expected %q, got %q", expected, pairs)
+	}
+}
+
+// TestCompareBatch tests that every pair is compared and reported in order,
+// however many workers are used
+func TestCompareBatch(t *testing.T) {
+	var pairs []BatchPair
+	for i := 0; i < 50; i++ {
+		actual := "same"
+		if i%7 == 0 {
+			actual = "Same"
+		}
+		pairs = append(pairs, BatchPair{"", "same", actual})
+	}
+
+	for _, workers := range []int{0, 1, 4, 100} {
+		results := CompareBatch(pairs, Options{}, workers)
+		if len(results) != len(pairs) {
+			t.Fatalf("%d workers: expected %d results, got %d", workers, len(pairs), len(results))
+		}
+		for i, res := range results {
+			if res.Equal() != (i%7 != 0) {
+				t.Errorf("%d workers: pair %d: unexpected result %+v", workers, i, res)
+			}
+		}
+		if n := CountMismatches(results); n != 8 {
+			t.Errorf("%d workers: expected 8 mismatches, got %d", workers, n)
+		}
+	}
+
+	results := CompareBatch(pairs[:1], Options{CaseInsensitive: true}, 2)
+	if !results[0].Equal() {
+		t.Errorf("Expected the options to be applied, got %+v", results[0])
+	}
+}
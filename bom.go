@@ -0,0 +1,253 @@
+package changecase
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// BOM - the byte order mark, which is written at the start of output by the
+// -bom option of the commands
+const BOM = "\ufeff"
+
+// Encoding - the encoding of input as detected from its byte order mark
+type Encoding string
+
+const (
+	EncodingUTF8    Encoding = "utf-8"     // no byte order mark
+	EncodingUTF8BOM Encoding = "utf-8-bom" // UTF-8 with a byte order mark
+	EncodingUTF16LE Encoding = "utf-16le"  // little-endian UTF-16 with a byte order mark
+	EncodingUTF16BE Encoding = "utf-16be"  // big-endian UTF-16 with a byte order mark
+)
+
+// DecodeReader - return a reader that yields the text of r as UTF-8, along
+// with the encoding it detected. A UTF-8 byte order mark is removed, and
+// UTF-16 that starts with a byte order mark is decoded as it is read; input
+// without one is passed through unchanged, since it is taken to be UTF-8.
+func DecodeReader(r io.Reader) (io.Reader, Encoding, error) {
+	reader := bufio.NewReader(r)
+	head, err := reader.Peek(3)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, EncodingUTF8, err
+	}
+	switch {
+	case len(head) >= 3 && head[0] == 0xEF && head[1] == 0xBB && head[2] == 0xBF:
+		reader.Discard(3)
+		return reader, EncodingUTF8BOM, nil
+	case len(head) >= 2 && head[0] == 0xFF && head[1] == 0xFE:
+		reader.Discard(2)
+		return &utf16Reader{reader: reader, bigEndian: false}, EncodingUTF16LE, nil
+	case len(head) >= 2 && head[0] == 0xFE && head[1] == 0xFF:
+		reader.Discard(2)
+		return &utf16Reader{reader: reader, bigEndian: true}, EncodingUTF16BE, nil
+	}
+	return reader, EncodingUTF8, nil
+}
+
+// RawReader - return r unchanged, taken to be UTF-8 without a byte order
+// mark. It stands in for DecodeReader when input must be read byte for byte,
+// such as binary data that happens to start like a byte order mark.
+func RawReader(r io.Reader) (io.Reader, Encoding, error) {
+	return r, EncodingUTF8, nil
+}
+
+// ReadAllDecoded - read all of r, decoded to UTF-8 as by DecodeReader
+func ReadAllDecoded(r io.Reader) ([]byte, error) {
+	decoded, _, err := DecodeReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(decoded)
+}
+
+// DecodeString - return s as UTF-8 text with any byte order mark removed,
+// decoding it first when it is UTF-16
+func DecodeString(s string) string {
+	if len(s) < 2 || (s[0] != 0xEF && s[0] != 0xFF && s[0] != 0xFE) {
+		return s
+	}
+	r, _, _ := DecodeReader(strings.NewReader(s))
+	data, _ := io.ReadAll(r)
+	return string(data)
+}
+
+// utf16Reader - decodes UTF-16 from reader into UTF-8. Unpaired surrogates
+// and a final odd byte become U+FFFD, the replacement character.
+type utf16Reader struct {
+	reader    *bufio.Reader
+	bigEndian bool
+	pending   []byte // encoded UTF-8 that did not fit in the last Read
+}
+
+// Read - fill p with decoded UTF-8
+func (ur *utf16Reader) Read(p []byte) (int, error) {
+	n := copy(p, ur.pending)
+	ur.pending = ur.pending[n:]
+	for n < len(p) {
+		r, err := ur.readRune()
+		if err == io.EOF {
+			if n == 0 {
+				return 0, io.EOF
+			}
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		var buf [utf8.UTFMax]byte
+		size := utf8.EncodeRune(buf[:], r)
+		copied := copy(p[n:], buf[:size])
+		n += copied
+		if copied < size {
+			ur.pending = append(ur.pending[:0], buf[copied:size]...)
+		}
+	}
+	return n, nil
+}
+
+// readRune - decode the next rune, joining surrogate pairs
+func (ur *utf16Reader) readRune() (rune, error) {
+	unit, err := ur.readUnit()
+	if err != nil {
+		return 0, err
+	}
+	if !utf16.IsSurrogate(rune(unit)) {
+		return rune(unit), nil
+	}
+	if unit >= 0xDC00 {
+		// a low surrogate must follow a high one
+		return utf8.RuneError, nil
+	}
+	head, err := ur.reader.Peek(2)
+	if len(head) < 2 {
+		if err == io.EOF {
+			return utf8.RuneError, nil
+		}
+		return 0, err
+	}
+	low := ur.unit(head)
+	if low < 0xDC00 || low > 0xDFFF {
+		return utf8.RuneError, nil
+	}
+	ur.reader.Discard(2)
+	return utf16.DecodeRune(rune(unit), rune(low)), nil
+}
+
+// readUnit - read the next 16-bit code unit; a final odd byte decodes as
+// U+FFFD
+func (ur *utf16Reader) readUnit() (uint16, error) {
+	var b [2]byte
+	n, err := io.ReadFull(ur.reader, b[:])
+	if err == io.ErrUnexpectedEOF {
+		return utf8.RuneError, nil
+	}
+	if n < 2 {
+		return 0, err
+	}
+	return ur.unit(b[:]), nil
+}
+
+// unit - decode a code unit in the byte order of the input
+func (ur *utf16Reader) unit(b []byte) uint16 {
+	if ur.bigEndian {
+		return uint16(b[0])<<8 | uint16(b[1])
+	}
+	return uint16(b[1])<<8 | uint16(b[0])
+}
+
+// EncodeWriter - return a writer that writes the UTF-8 text given to it to w
+// in encoding, after the byte order mark of that encoding, so text read
+// through DecodeReader can be written back the way it was stored. Invalid
+// UTF-8 becomes U+FFFD in UTF-16. Close writes out a rune left incomplete by
+// the last Write; it does not close w.
+func EncodeWriter(w io.Writer, encoding Encoding) (io.WriteCloser, error) {
+	switch encoding {
+	case EncodingUTF8BOM:
+		if _, err := io.WriteString(w, BOM); err != nil {
+			return nil, err
+		}
+	case EncodingUTF16LE, EncodingUTF16BE:
+		uw := &utf16Writer{writer: w, bigEndian: encoding == EncodingUTF16BE}
+		if _, err := io.WriteString(uw, BOM); err != nil {
+			return nil, err
+		}
+		return uw, nil
+	}
+	return nopWriteCloser{w}, nil
+}
+
+// nopWriteCloser - a writer with a Close method that does nothing
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close - do nothing
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// utf16Writer - encodes UTF-8 into UTF-16 on writer
+type utf16Writer struct {
+	writer    io.Writer
+	bigEndian bool
+	pending   []byte // the start of a rune split across two writes
+}
+
+// Write - encode p, holding back a rune that continues in the next Write
+func (uw *utf16Writer) Write(p []byte) (int, error) {
+	data := append(uw.pending, p...)
+	keep := 0
+	for i := len(data) - 1; i >= 0 && i > len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				keep = len(data) - i
+			}
+			break
+		}
+	}
+	if err := uw.encode(data[:len(data)-keep]); err != nil {
+		return 0, err
+	}
+	uw.pending = append([]byte(nil), data[len(data)-keep:]...)
+	return len(p), nil
+}
+
+// Close - encode a rune left incomplete by the last Write as U+FFFD
+func (uw *utf16Writer) Close() error {
+	err := uw.encode(uw.pending)
+	uw.pending = nil
+	return err
+}
+
+// encode - write data as UTF-16 code units in the byte order of the output
+func (uw *utf16Writer) encode(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	out := make([]byte, 0, 2*len(data))
+	var units []uint16
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		data = data[size:]
+		units = utf16.AppendRune(units[:0], r)
+		for _, unit := range units {
+			if uw.bigEndian {
+				out = append(out, byte(unit>>8), byte(unit))
+			} else {
+				out = append(out, byte(unit), byte(unit>>8))
+			}
+		}
+	}
+	_, err := uw.writer.Write(out)
+	return err
+}
+
+// StdinIsPiped - report whether standard input is a pipe or file rather than
+// a terminal, so a command may read it instead of expecting arguments
+func StdinIsPiped() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
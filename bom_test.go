@@ -0,0 +1,107 @@
+package changecase
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// TestDecodeReader tests byte order mark detection and UTF-16 decoding
+func TestDecodeReader(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		encoding Encoding
+		expected string
+	}{
+		{"plain", "héllo\n", EncodingUTF8, "héllo\n"},
+		{"empty", "", EncodingUTF8, ""},
+		{"one byte", "a", EncodingUTF8, "a"},
+		{"utf-8 bom", "\xEF\xBB\xBFhéllo", EncodingUTF8BOM, "héllo"},
+		{"bom only", "\xEF\xBB\xBF", EncodingUTF8BOM, ""},
+		{"bom not at start", "a\xEF\xBB\xBF", EncodingUTF8, "a\xEF\xBB\xBF"},
+		{"utf-16le", "\xFF\xFEh\x00\xE9\x00\r\x00\n\x00", EncodingUTF16LE, "hé\r\n"},
+		{"utf-16be", "\xFE\xFF\x00h\x00\xE9", EncodingUTF16BE, "hé"},
+		{"utf-16le surrogate pair", "\xFF\xFE\x3D\xD8\x4B\xDC!\x00", EncodingUTF16LE, "👋!"},
+		{"utf-16le cjk", "\xFF\xFE\xE5\x65\x2C\x67", EncodingUTF16LE, "日本"},
+		{"unpaired high surrogate", "\xFF\xFE\x3D\xD8a\x00", EncodingUTF16LE, "�a"},
+		{"unpaired low surrogate", "\xFF\xFE\x4B\xDCa\x00", EncodingUTF16LE, "�a"},
+		{"high surrogate at end", "\xFF\xFEa\x00\x3D\xD8", EncodingUTF16LE, "a�"},
+		{"odd byte", "\xFF\xFEa\x00b", EncodingUTF16LE, "a�"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, wrap := range []func(io.Reader) io.Reader{func(r io.Reader) io.Reader { return r }, iotest.OneByteReader} {
+				r, encoding, err := DecodeReader(wrap(strings.NewReader(tt.input)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				// reading a byte at a time splits every multi-byte rune
+				data, err := io.ReadAll(iotest.OneByteReader(r))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if encoding != tt.encoding || string(data) != tt.expected {
+					t.Errorf("Expected %s %q, got %s %q", tt.encoding, tt.expected, encoding, string(data))
+				}
+			}
+			if got := DecodeString(tt.input); got != tt.expected {
+				t.Errorf("DecodeString: expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestEncodeWriter tests that text decoded by DecodeReader is written back in
+// its original encoding, even when runes are split across writes
+func TestEncodeWriter(t *testing.T) {
+	inputs := []string{
+		"héllo\n",
+		"",
+		"\xEF\xBB\xBFhéllo",
+		"\xFF\xFEh\x00\xE9\x00\r\x00\n\x00",
+		"\xFE\xFF\x00h\x00\xE9",
+		"\xFF\xFE\x3D\xD8\x4B\xDC!\x00",
+		"\xFE\xFF\xD8\x3D\xDC\x4B",
+		"\xFF\xFE\xE5\x65\x2C\x67",
+	}
+
+	for _, input := range inputs {
+		r, encoding, err := DecodeReader(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		text, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var out strings.Builder
+		w, err := EncodeWriter(&out, encoding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range text {
+			if _, err := w.Write(text[i : i+1]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != input {
+			t.Errorf("Expected %q written back as %s, got %q", input, encoding, out.String())
+		}
+	}
+
+	// an incomplete rune at the end is written as U+FFFD
+	var out strings.Builder
+	w, _ := EncodeWriter(&out, EncodingUTF16LE)
+	io.WriteString(w, "a\xE6\x97")
+	w.Close()
+	if expected := "\xFF\xFEa\x00\xFD\xFF\xFD\xFF"; out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+}
@@ -2,7 +2,7 @@ package changecase
 
 import (
 	"fmt"
-	"os"
+	"strconv"
 	"strings"
 	"unicode"
 )
@@ -15,11 +15,32 @@ func Usage(pgmName string) {
 	fmt.Printf("%s, v%s\n", pgmName, PgmVersion)
 	fmt.Println(PgmUrl)
 	fmt.Println()
-	fmt.Printf("usage: %s [arguments]\n", pgmName)
+	fmt.Printf("usage: %s [-format json|text|tsv] [-bom] [arguments]\n", pgmName)
 	fmt.Println("(consider surrounding command-line arguments in double-quotes to preserve spacing)")
+	fmt.Println("Without arguments, standard input is converted; a byte order mark is removed and UTF-16 is decoded.")
+	fmt.Println("-bom starts the output with a UTF-8 byte order mark.")
 	fmt.Println()
 }
 
+// Unescape - interpret Go escapes such as \n, \r, \t, \x00 and \u2029 in a
+// string given on the command line
+func Unescape(s string) (string, error) {
+	var sb strings.Builder
+	for len(s) > 0 {
+		r, multibyte, tail, err := strconv.UnquoteChar(s, 0)
+		if err != nil {
+			return "", fmt.Errorf("invalid escape in %q", s)
+		}
+		if r < 0x80 && !multibyte {
+			sb.WriteByte(byte(r))
+		} else {
+			sb.WriteRune(r)
+		}
+		s = tail
+	}
+	return sb.String(), nil
+}
+
 // Lower - return a lower case string
 func Lower(args []string) string {
 	output := ""
@@ -41,7 +62,7 @@ func Upper(args []string) string {
 // TitleCase - return a title case string
 func TitleCase(args []string) string {
 	output := ""
-	for _, arg := range os.Args[1:] {
+	for _, arg := range args {
 		output += title(arg) + " "
 	}
 	return output[:len(output)-1]
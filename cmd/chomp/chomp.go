@@ -1,23 +1,193 @@
 // chomp reads from standard input and outputs all content except a trailing newline, mimicking Perl's chomp functionality.
-// It preserves internal newlines while removing only the final newline character if present.
+// It preserves internal newlines while removing only the final newline character if present. Options remove a
+// trailing CRLF or CR instead, any separator string (like Perl's $/), or every trailing newline (paragraph mode);
+// others remove the last character (like Perl's chop) or append a newline only when one is missing. Files given
+// as arguments are each chomped on their own, and -i rewrites them in place. Input that starts with a byte order mark
+// is decoded from UTF-16, or has its UTF-8 byte order mark removed, before it is chomped, unless -raw is given; -i
+// writes each file back in the encoding it was read in.
 
 package main
 
 import (
-	"bufio"
+	"bytes"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"unicode/utf8"
 
 	"github.com/jftuga/changecase"
 )
 
 const pgmName string = "chomp"
 
+// chompAction is what is done to the end of the input
+type chompAction int
+
+const (
+	actionChomp  chompAction = iota // remove one trailing separator
+	actionAll                       // remove every trailing separator
+	actionChop                      // remove the last character, whatever it is
+	actionEnsure                    // append a separator unless one is already there
+)
+
+// chompMode describes what is removed from, or added to, the end of the input
+type chompMode struct {
+	separators [][]byte // the first of these that ends the input is removed
+	action     chompAction
+}
+
+// newChompMode builds the mode selected by the command-line options. Only
+// one of -crlf, -cr, -s and -p, and one of -a, -chop and -ensure, may be
+// given; with none of them a single trailing "\n" is removed
+func newChompMode(crlf, cr bool, separator string, hasSeparator, paragraph, all, chop, ensure bool) (chompMode, error) {
+	if count(crlf, cr, hasSeparator, paragraph) > 1 {
+		return chompMode{}, errors.New("-crlf, -cr, -s and -p cannot be combined")
+	}
+	if count(all || paragraph, chop, ensure) > 1 {
+		return chompMode{}, errors.New("-a (or -p), -chop and -ensure cannot be combined")
+	}
+	if chop && count(crlf, cr, hasSeparator) > 0 {
+		return chompMode{}, errors.New("-chop removes one character, so it cannot be combined with -crlf, -cr or -s")
+	}
+
+	mode := chompMode{separators: [][]byte{[]byte("\n")}}
+	switch {
+	case crlf:
+		// a file that turns out to use LF endings is chomped as usual
+		mode.separators = [][]byte{[]byte("\r\n"), []byte("\n")}
+	case cr:
+		mode.separators = [][]byte{[]byte("\r")}
+	case hasSeparator:
+		sep, err := changecase.Unescape(separator)
+		if err != nil {
+			return chompMode{}, err
+		}
+		if sep == "" {
+			return chompMode{}, errors.New("-s needs a non-empty separator (use -p for paragraph mode)")
+		}
+		mode.separators = [][]byte{[]byte(sep)}
+	}
+
+	switch {
+	case all || paragraph:
+		// paragraph mode is Perl's name for removing every trailing newline
+		mode.action = actionAll
+	case chop:
+		mode.action = actionChop
+	case ensure:
+		mode.action = actionEnsure
+	}
+	return mode, nil
+}
+
+// count returns how many of the options are set
+func count(options ...bool) int {
+	n := 0
+	for _, set := range options {
+		if set {
+			n++
+		}
+	}
+	return n
+}
+
+// longest returns the length of the longest separator
+func (mode chompMode) longest() int {
+	n := 0
+	for _, sep := range mode.separators {
+		n = max(n, len(sep))
+	}
+	return n
+}
+
+// holdBack returns how many bytes at the start of held can no longer be
+// changed, whatever follows them
+func (mode chompMode) holdBack(held []byte) int {
+	switch mode.action {
+	case actionAll:
+		// keep the run of separators, plus what may be the start of another
+		n := len(mode.trimAll(held))
+		for k := 1; k < mode.longest() && k <= len(held); k++ {
+			if mode.startsSeparator(held[len(held)-k:]) {
+				n = min(n, len(mode.trimAll(held[:len(held)-k])))
+			}
+		}
+		return n
+	case actionChop:
+		return max(0, len(held)-utf8.UTFMax)
+	}
+	return max(0, len(held)-mode.longest())
+}
+
+// startsSeparator reports whether tail is the start of a separator
+func (mode chompMode) startsSeparator(tail []byte) bool {
+	for _, sep := range mode.separators {
+		if bytes.HasPrefix(sep, tail) {
+			return true
+		}
+	}
+	return false
+}
+
+// trimSeparator returns data without the first separator that ends it, and
+// whether there was one
+func (mode chompMode) trimSeparator(data []byte) ([]byte, bool) {
+	for _, sep := range mode.separators {
+		if bytes.HasSuffix(data, sep) {
+			return data[:len(data)-len(sep)], true
+		}
+	}
+	return data, false
+}
+
+// trimAll returns data without every separator that ends it
+func (mode chompMode) trimAll(data []byte) []byte {
+	for {
+		trimmed, ok := mode.trimSeparator(data)
+		if !ok {
+			return data
+		}
+		data = trimmed
+	}
+}
+
+// apply returns data with its end changed according to the mode; data is
+// either the whole input or everything from where holdBack stopped flushing
+func (mode chompMode) apply(data []byte) []byte {
+	switch mode.action {
+	case actionAll:
+		return mode.trimAll(data)
+	case actionChop:
+		// an invalid UTF-8 sequence is removed one byte at a time
+		_, size := utf8.DecodeLastRune(data)
+		return data[:len(data)-size]
+	case actionEnsure:
+		if _, ok := mode.trimSeparator(data); ok || len(data) == 0 {
+			return data
+		}
+		return append(data, mode.separators[0]...)
+	}
+	trimmed, _ := mode.trimSeparator(data)
+	return trimmed
+}
+
 func main() {
 	versionFlag := flag.Bool("version", false, "Display version information")
 	helpFlag := flag.Bool("help", false, "Display help information")
+	crlfFlag := flag.Bool("crlf", false, "Remove a trailing \"\\r\\n\" (or a lone \"\\n\")")
+	crFlag := flag.Bool("cr", false, "Remove a trailing \"\\r\"")
+	separatorFlag := flag.String("s", "", "Remove this trailing separator, which may contain escapes such as \\r\\n")
+	paragraphFlag := flag.Bool("p", false, "Paragraph mode: remove every trailing newline")
+	allFlag := flag.Bool("a", false, "Remove every trailing newline (or separator), not just one")
+	chopFlag := flag.Bool("chop", false, "Remove the last character, like Perl's chop")
+	ensureFlag := flag.Bool("ensure", false, "Append a newline (or separator) unless the input already ends with one")
+	inPlaceFlag := flag.Bool("i", false, "Rewrite each file in place instead of writing to standard output")
+	bomFlag := flag.Bool("bom", false, "Start the output, or each rewritten UTF-8 file, with a UTF-8 byte order mark")
+	rawFlag := flag.Bool("raw", false, "Read input as raw bytes, without decoding UTF-16 or removing a byte order mark")
+	formatFlag := flag.String("format", changecase.FormatText, "Output format: text, json, tsv")
 	flag.Parse()
 
 	if *versionFlag || *helpFlag {
@@ -27,43 +197,298 @@ func main() {
 		fmt.Println("Usage:")
 		fmt.Println("  chomp < input.txt")
 		fmt.Println("  echo 'text' | chomp")
+		fmt.Println("  chomp -crlf < windows.txt")
+		fmt.Println("  chomp -s '\\x00' < records.bin")
+		fmt.Println("  chomp -a -crlf < windows.txt")
+		fmt.Println("  chomp -ensure < no-final-newline.txt")
+		fmt.Println("  chomp -format json < input.txt")
+		fmt.Println("  chomp a.txt b.txt > joined.txt")
+		fmt.Println("  chomp -i -a secrets/*.key")
 		fmt.Println()
-		fmt.Println("Removes trailing newline from standard input while preserving internal newlines.")
+		fmt.Println("Removes trailing newline from standard input, or from each file given, while preserving internal newlines.")
 		fmt.Println("Mimics Perl's chomp functionality.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fmt.Println("  -crlf: Remove a trailing \"\\r\\n\", or a lone \"\\n\" if the input ends with one")
+		fmt.Println("  -cr: Remove a trailing \"\\r\"")
+		fmt.Println("  -s separator: Remove this trailing separator, like Perl's $/; escapes such as \\n, \\t and \\x00 are allowed")
+		fmt.Println("  -p: Paragraph mode: remove every trailing newline, like Perl's chomp with $/ set to \"\"")
+		fmt.Println("  -a: Remove every trailing newline, or every trailing separator given by -crlf, -cr or -s")
+		fmt.Println("  -chop: Remove the last character, whatever it is, like Perl's chop; multi-byte UTF-8 characters are removed whole")
+		fmt.Println("  -ensure: Append a newline unless the input already ends with one (or the separator given by -crlf, -cr or -s);")
+		fmt.Println("           empty input is left empty")
+		fmt.Println("  -i: Rewrite each file in place; the new contents are written to a temporary file in the same")
+		fmt.Println("      directory, which then replaces the original, so a file is never left half written. A file")
+		fmt.Println("      keeps its encoding: UTF-16 is written back as UTF-16, and a UTF-8 byte order mark is kept")
+		fmt.Println("  -bom: Start the output, or each UTF-8 file rewritten by -i, with a UTF-8 byte order mark")
+		fmt.Println("  -raw: Read input as raw bytes, without decoding UTF-16 or removing a UTF-8 byte order mark, for")
+		fmt.Println("        binary input such as records that happen to start with the bytes of a byte order mark")
+		fmt.Println("  -format: Output format: text (default), json, tsv")
+		fmt.Println("Input is decoded from UTF-16 when it starts with a byte order mark, and a UTF-8 byte order mark is removed,")
+		fmt.Println("unless -raw is given.")
+		fmt.Println("Files are chomped independently and written to standard output one after the other; \"-\" reads")
+		fmt.Println("standard input. With -format json or tsv, reports the input, the output and whether they differ,")
+		fmt.Println("with a record per file that starts with its name.")
+		return
+	}
+
+	hasSeparator := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "s" {
+			hasSeparator = true
+		}
+	})
+	mode, err := newChompMode(*crlfFlag, *crFlag, *separatorFlag, hasSeparator, *paragraphFlag, *allFlag, *chopFlag, *ensureFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	format, err := changecase.ParseFormat(*formatFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *bomFlag && format != changecase.FormatText {
+		fmt.Fprintln(os.Stderr, "Error: -bom only applies to text output")
+		os.Exit(1)
+	}
+	if *rawFlag {
+		decodeInput = changecase.RawReader
+	}
+	files := flag.Args()
+	if *inPlaceFlag {
+		if format != changecase.FormatText {
+			fmt.Fprintln(os.Stderr, "Error: -i cannot be combined with -format")
+			os.Exit(1)
+		}
+		if len(files) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: -i needs at least one file")
+			os.Exit(1)
+		}
+	}
+	if format != changecase.FormatText {
+		writeRecords(format, mode, files)
 		return
 	}
+	bom := ""
+	if *bomFlag {
+		bom = changecase.BOM
+	}
+	if len(files) == 0 {
+		if err := chompFile("-", bom, mode); err != nil {
+			fmt.Fprintf(os.Stderr, "Error %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if !*inPlaceFlag {
+		// the output of every file is joined, so it has a single byte order mark
+		fmt.Print(bom)
+	}
+
+	// a file that cannot be chomped is reported, and the rest still are
+	failed := false
+	for _, name := range files {
+		var err error
+		if *inPlaceFlag {
+			err = chompInPlace(name, *bomFlag, mode)
+		} else {
+			err = chompFile(name, "", mode)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", name, err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// decodeInput detects the encoding of input and decodes it to UTF-8; -raw
+// replaces it with changecase.RawReader, which leaves input as it is
+var decodeInput = changecase.DecodeReader
+
+// openInput opens a file argument, where "-" is standard input
+func openInput(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(name)
+}
+
+// chompFile writes the chomped contents of a file to standard output,
+// preceded by bom
+func chompFile(name, bom string, mode chompMode) error {
+	f, err := openInput(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, _, err := decodeInput(f)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(os.Stdout, bom); err != nil {
+		return err
+	}
+	return chompStream(r, os.Stdout, mode, blockSize)
+}
+
+// chompInPlace replaces a file with its chomped contents. They are written to
+// a temporary file in the same directory, which is renamed over the original
+// once complete, so readers see either the old or the new contents. The
+// file keeps its permissions, and a symbolic link keeps pointing at it. The
+// new contents are written in the encoding the file was read in, or as UTF-8
+// with a byte order mark when bom is set and the file is plain UTF-8.
+func chompInPlace(name string, bom bool, mode chompMode) (err error) {
+	if name == "-" {
+		return errors.New("-i cannot rewrite standard input")
+	}
+	path, err := filepath.EvalSymlinks(name)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return errors.New("not a regular file")
+	}
+
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".chomp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
 
-	reader := bufio.NewReader(os.Stdin)
-	writer := bufio.NewWriter(os.Stdout)
-	defer writer.Flush()
+	r, encoding, err := decodeInput(in)
+	if err != nil {
+		return err
+	}
+	if bom && encoding == changecase.EncodingUTF8 {
+		encoding = changecase.EncodingUTF8BOM
+	}
+	w, err := changecase.EncodeWriter(tmp, encoding)
+	if err != nil {
+		return err
+	}
+	if err = chompStream(r, w, mode, blockSize); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
 
-	var lastByte byte
-	var hasBytes bool
+// blockSize is how much input chompStream reads at a time
+const blockSize = 64 * 1024
 
+// chompStream copies r to w in blocks of up to size bytes, holding back only
+// the bytes at the end of what has been read that the mode may still change,
+// and applies the mode to them once r is exhausted
+func chompStream(r io.Reader, w io.Writer, mode chompMode, size int) error {
+	// held starts with the bytes held back from earlier blocks, and each
+	// block is read straight into the space after them
+	held := make([]byte, 0, size+mode.longest())
 	for {
-		b, err := reader.ReadByte()
+		if cap(held)-len(held) < size {
+			held = append(make([]byte, 0, 2*cap(held)+size), held...)
+		}
+		n, err := r.Read(held[len(held) : len(held)+size])
+		held = held[:len(held)+n]
+		if n > 0 {
+			if done := mode.holdBack(held); done > 0 {
+				if _, err := w.Write(held[:done]); err != nil {
+					return fmt.Errorf("writing output: %w", err)
+				}
+				held = held[:copy(held, held[done:])]
+			}
+		}
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("reading input: %w", err)
 		}
+	}
 
-		if hasBytes {
-			if err := writer.WriteByte(lastByte); err != nil {
-				fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
-				os.Exit(1)
-			}
-		}
-		lastByte = b
-		hasBytes = true
+	if _, err := w.Write(mode.apply(held)); err != nil {
+		return fmt.Errorf("writing output: %w", err)
 	}
+	return nil
+}
 
-	if hasBytes && lastByte != '\n' {
-		if err := writer.WriteByte(lastByte); err != nil {
+// writeRecords reports standard input, or each file, before and after
+// chomping as structured records; the records of files start with the name
+func writeRecords(format string, mode chompMode, files []string) {
+	names := files
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+	var records [][]changecase.Field
+	failed := false
+	for _, name := range names {
+		input, err := readInput(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", name, err)
+			failed = true
+			continue
+		}
+		output := mode.apply(bytes.Clone(input))
+		fields := []changecase.Field{
+			{Name: "input", Value: string(input)},
+			{Name: "output", Value: string(output)},
+			{Name: "chomped", Value: !bytes.Equal(output, input)},
+		}
+		if len(files) > 0 {
+			fields = append([]changecase.Field{{Name: "file", Value: name}}, fields...)
+		}
+		records = append(records, fields)
+	}
+	if len(records) > 0 {
+		if err := changecase.WriteRecords(os.Stdout, format, records); err != nil {
 			fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
 			os.Exit(1)
 		}
 	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// readInput reads all of a file argument, where "-" is standard input,
+// decoded to UTF-8
+func readInput(name string) ([]byte, error) {
+	f, err := openInput(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, _, err := decodeInput(f)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
 }
@@ -1,9 +1,16 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestChomp(t *testing.T) {
@@ -34,10 +41,235 @@ func TestChomp(t *testing.T) {
 	}
 }
 
+// TestChompFormat tests the structured output of -format json
+func TestChompFormat(t *testing.T) {
+	cmd := exec.Command("go", "run", "chomp.go", "-format", "json")
+	var stdout bytes.Buffer
+	cmd.Stdin = bytes.NewReader([]byte("a\tb\n"))
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Error running chomp: %v", err)
+	}
+
+	expected := `{"input":"a\tb\n","output":"a\tb","chomped":true}` + "\n"
+	if stdout.String() != expected {
+		t.Errorf("Expected: %q\nGot: %q", expected, stdout.String())
+	}
+}
+
+// TestChompSeparators tests the -crlf, -cr, -s and -p options
+func TestChompSeparators(t *testing.T) {
+	tests := []struct {
+		args     []string
+		input    string
+		expected string
+	}{
+		{[]string{"-crlf"}, "Line 1\r\nLine 2\r\n", "Line 1\r\nLine 2"},
+		{[]string{"-crlf"}, "Line 1\n", "Line 1"},
+		{[]string{"-crlf"}, "Line 1\r\n\r\n", "Line 1\r\n"},
+		{[]string{"-cr"}, "Line 1\r", "Line 1"},
+		{[]string{"-cr"}, "Line 1\r\n", "Line 1\r\n"},
+		{[]string{"-s", "\\r\\n"}, "a\r\n", "a"},
+		{[]string{"-s", "END"}, "a\nEND", "a\n"},
+		{[]string{"-s", "END"}, "END", ""},
+		{[]string{"-s", "\\x00"}, "a\x00b\x00", "a\x00b"},
+		{[]string{"-p"}, "para\n\n\n", "para"},
+		{[]string{"-p"}, "a\n\nb\n\n", "a\n\nb"},
+		{[]string{"-p"}, "\n\n", ""},
+		{[]string{"-p"}, "a\r\n", "a\r"},
+	}
+
+	for _, test := range tests {
+		output, err := runChomp(test.input, test.args...)
+		if err != nil {
+			t.Fatalf("Error running chomp %v: %v", test.args, err)
+		}
+		if output != test.expected {
+			t.Errorf("Args: %v\nInput: %q\nExpected: %q\nGot: %q", test.args, test.input, test.expected, output)
+		}
+	}
+
+	// Test with options that cannot be combined and an empty separator
+	for _, args := range [][]string{{"-crlf", "-p"}, {"-s", ""}, {"-s", "\\q"}} {
+		if _, err := runChomp("a\n", args...); err == nil {
+			t.Errorf("Expected an error for %v, got none", args)
+		}
+	}
+}
+
+// TestChompModes tests the -a, -chop and -ensure options
+func TestChompModes(t *testing.T) {
+	tests := []struct {
+		args     []string
+		input    string
+		expected string
+	}{
+		{[]string{"-a"}, "Line 1\n\n\n", "Line 1"},
+		{[]string{"-a"}, "Line 1\nLine 2", "Line 1\nLine 2"},
+		{[]string{"-a", "-crlf"}, "Line 1\r\n\n\r\n", "Line 1"},
+		{[]string{"-a", "-s", "ab"}, "xabab", "x"},
+		{[]string{"-a", "-s", "ab"}, "xabaab", "xaba"},
+		{[]string{"-chop"}, "Hello!", "Hello"},
+		{[]string{"-chop"}, "naïve café", "naïve caf"},
+		{[]string{"-chop"}, "👋", ""},
+		{[]string{"-chop"}, "a\r\n", "a\r"},
+		{[]string{"-chop"}, "", ""},
+		{[]string{"-ensure"}, "Line 1", "Line 1\n"},
+		{[]string{"-ensure"}, "Line 1\n", "Line 1\n"},
+		{[]string{"-ensure"}, "", ""},
+		{[]string{"-ensure", "-crlf"}, "Line 1", "Line 1\r\n"},
+		{[]string{"-ensure", "-crlf"}, "Line 1\n", "Line 1\n"},
+	}
+
+	for _, test := range tests {
+		output, err := runChomp(test.input, test.args...)
+		if err != nil {
+			t.Fatalf("Error running chomp %v: %v", test.args, err)
+		}
+		if output != test.expected {
+			t.Errorf("Args: %v\nInput: %q\nExpected: %q\nGot: %q", test.args, test.input, test.expected, output)
+		}
+	}
+
+	// Test with modes that cannot be combined
+	for _, args := range [][]string{{"-a", "-chop"}, {"-p", "-ensure"}, {"-chop", "-crlf"}} {
+		if _, err := runChomp("a\n", args...); err == nil {
+			t.Errorf("Expected an error for %v, got none", args)
+		}
+	}
+}
+
+// TestChompFiles tests file arguments and in-place editing
+func TestChompFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, contents string, perm os.FileMode) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(contents), perm); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	first := write("first.txt", "one\n", 0644)
+	second := write("second.key", "two\n\n", 0600)
+	link := filepath.Join(dir, "link.key")
+	if err := os.Symlink(second, link); err != nil {
+		t.Fatal(err)
+	}
+
+	// each file is chomped on its own, and "-" reads standard input
+	output, err := runChomp("stdin\n", first, "-", second)
+	if err != nil {
+		t.Fatalf("Error running chomp: %v", err)
+	}
+	if expected := "onestdintwo\n"; output != expected {
+		t.Errorf("Expected: %q\nGot: %q", expected, output)
+	}
+
+	// in-place editing rewrites the files, following symbolic links
+	if output, err := runChomp("", "-i", "-a", first, link); err != nil || output != "" {
+		t.Fatalf("Error running chomp -i: %v (output %q)", err, output)
+	}
+	for path, expected := range map[string]string{first: "one", second: "two"} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected {
+			t.Errorf("%s: expected %q, got %q", filepath.Base(path), expected, string(data))
+		}
+	}
+	if info, err := os.Stat(second); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected permissions 0600 to be kept, got %v (%v)", info.Mode().Perm(), err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Expected %s to still be a symbolic link", link)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 3 {
+		t.Errorf("Expected no temporary files to be left behind, got %d entries", len(entries))
+	}
+
+	// a missing file is reported, but the others are still rewritten
+	third := write("third.txt", "three\n", 0644)
+	if _, err := runChomp("", "-i", filepath.Join(dir, "missing.txt"), third); err == nil {
+		t.Errorf("Expected an error for a missing file, got none")
+	}
+	if data, _ := os.ReadFile(third); string(data) != "three" {
+		t.Errorf("Expected the remaining file to be chomped, got %q", string(data))
+	}
+
+	// Test with -i and no files, standard input and -format
+	for _, args := range [][]string{{"-i"}, {"-i", "-"}, {"-i", "-format", "json", third}} {
+		if _, err := runChomp("a\n", args...); err == nil {
+			t.Errorf("Expected an error for %v, got none", args)
+		}
+	}
+}
+
+// TestChompEncodings tests byte order marks, UTF-16 input and -bom
+func TestChompEncodings(t *testing.T) {
+	tests := []struct {
+		args     []string
+		input    string
+		expected string
+	}{
+		{nil, "\xEF\xBB\xBFLine 1\n", "Line 1"},
+		{nil, "\xFF\xFEh\x00\xE9\x00\n\x00", "hé"},
+		{[]string{"-crlf"}, "\xFE\xFF\x00a\x00\r\x00\n", "a"},
+		{[]string{"-chop"}, "\xFF\xFE\x3D\xD8\x4B\xDC", ""},
+		{[]string{"-bom"}, "\xEF\xBB\xBFLine 1\n", "\xEF\xBB\xBFLine 1"},
+		{[]string{"-bom"}, "", "\xEF\xBB\xBF"},
+		{[]string{"-format", "json"}, "\xEF\xBB\xBFa\n", `{"input":"a\n","output":"a","chomped":true}` + "\n"},
+		{[]string{"-raw"}, "\xEF\xBB\xBFLine 1\n", "\xEF\xBB\xBFLine 1"},
+		{[]string{"-raw", "-s", `\x00`}, "\xFF\xFE\x01\x02\n", "\xFF\xFE\x01\x02\n"},
+		{[]string{"-raw", "-s", `\x00`}, "\xFF\xFE\x01\x02\x00", "\xFF\xFE\x01\x02"},
+	}
+
+	for _, test := range tests {
+		output, err := runChomp(test.input, test.args...)
+		if err != nil {
+			t.Fatalf("Error running chomp %v: %v", test.args, err)
+		}
+		if output != test.expected {
+			t.Errorf("Args: %v\nInput: %q\nExpected: %q\nGot: %q", test.args, test.input, test.expected, output)
+		}
+	}
+
+	// in-place editing writes each file back in the encoding it was read in
+	inPlace := []struct {
+		args     []string
+		contents string
+		expected string
+	}{
+		{nil, "\xFF\xFEa\x00\n\x00", "\xFF\xFEa\x00"},
+		{[]string{"-crlf"}, "\xFE\xFF\x00a\x00\r\x00\n", "\xFE\xFF\x00a"},
+		{nil, "\xEF\xBB\xBFa\n", "\xEF\xBB\xBFa"},
+		{[]string{"-chop"}, "\xFF\xFE\x3D\xD8\x4B\xDCa\x00", "\xFF\xFE\x3D\xD8\x4B\xDC"},
+		{[]string{"-bom"}, "\xFF\xFEa\x00\n\x00", "\xFF\xFEa\x00"},
+		{[]string{"-bom"}, "a\n", "\xEF\xBB\xBFa"},
+		{[]string{"-raw"}, "\xFF\xFEa\x00\n", "\xFF\xFEa\x00"},
+	}
+	for i, test := range inPlace {
+		path := filepath.Join(t.TempDir(), fmt.Sprintf("in-place-%d.txt", i))
+		if err := os.WriteFile(path, []byte(test.contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := runChomp("", append(append([]string{"-i"}, test.args...), path)...); err != nil {
+			t.Fatalf("Error running chomp -i %v: %v", test.args, err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != test.expected {
+			t.Errorf("Args: -i %v\nContents: %q\nExpected: %q\nGot: %q", test.args, test.contents, test.expected, string(data))
+		}
+	}
+
+	if _, err := runChomp("a\n", "-bom", "-format", "json"); err == nil {
+		t.Errorf("Expected an error for -bom with -format json, got none")
+	}
+}
+
 // runChomp simulates running the chomp program with the given input
-func runChomp(input string) (string, error) {
+func runChomp(input string, args ...string) (string, error) {
 	// Create a command to run the chomp program
-	cmd := exec.Command("go", "run", "chomp.go")
+	cmd := exec.Command("go", append([]string{"run", "chomp.go"}, args...)...)
 
 	// Create pipes for stdin and stdout
 	var stdout bytes.Buffer
@@ -53,3 +285,194 @@ func runChomp(input string) (string, error) {
 	// Return the output as a string
 	return stdout.String(), nil
 }
+
+// chompPerByte is the first mode-aware implementation of chompStream, which
+// reads one byte at a time and writes whatever holdBack releases. It is kept
+// as the reference that chompStream must match byte for byte in every mode.
+func chompPerByte(r io.Reader, w io.Writer, mode chompMode) error {
+	reader := bufio.NewReader(r)
+	writer := bufio.NewWriter(w)
+	defer writer.Flush()
+
+	var held []byte
+	for {
+		b, err := reader.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		held = append(held, b)
+		if n := mode.holdBack(held); n > 0 {
+			if _, err := writer.Write(held[:n]); err != nil {
+				return err
+			}
+			held = append(held[:0], held[n:]...)
+		}
+	}
+	_, err := writer.Write(mode.apply(held))
+	return err
+}
+
+// chompLastByte is the loop chomp used before it had any options: it writes
+// every byte but the last one, and the last one too unless it is '\n'. The
+// default mode must match it byte for byte.
+func chompLastByte(r io.Reader, w io.Writer) error {
+	reader := bufio.NewReader(r)
+	writer := bufio.NewWriter(w)
+	defer writer.Flush()
+
+	var lastByte byte
+	var hasBytes bool
+	for {
+		b, err := reader.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if hasBytes {
+			if err := writer.WriteByte(lastByte); err != nil {
+				return err
+			}
+		}
+		lastByte = b
+		hasBytes = true
+	}
+	if hasBytes && lastByte != '\n' {
+		return writer.WriteByte(lastByte)
+	}
+	return nil
+}
+
+// testModes returns every mode with a name for test and benchmark output
+func testModes(t testing.TB) map[string]chompMode {
+	modes := make(map[string]chompMode)
+	for _, args := range [][]string{{}, {"-crlf"}, {"-cr"}, {"-s", "END"}, {"-s", "aa"}, {"-p"},
+		{"-a"}, {"-a", "-crlf"}, {"-a", "-s", "ab"}, {"-chop"}, {"-ensure"}, {"-ensure", "-crlf"}} {
+		has := func(name string) bool {
+			for _, arg := range args {
+				if arg == name {
+					return true
+				}
+			}
+			return false
+		}
+		separator := ""
+		if has("-s") {
+			separator = args[len(args)-1]
+		}
+		mode, err := newChompMode(has("-crlf"), has("-cr"), separator, has("-s"), has("-p"), has("-a"), has("-chop"), has("-ensure"))
+		if err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		modes[strings.Join(args, " ")] = mode
+	}
+	return modes
+}
+
+// TestChompStream tests that the block-based chompStream produces exactly
+// the same output as the per-byte implementation, whatever the block size
+// and however the reader splits the input, and that the default mode still
+// matches the original chomp
+func TestChompStream(t *testing.T) {
+	inputs := []string{"", "\n", "\n\n\n", "a", "a\n", "a\r\n", "a\r\n\r\n\n", "a\r", "aENDEND", "aEN",
+		"xabab", "xabaab", "aaaaa", "héé", "👋\n", "\xff\xfe", "line 1\nline 2\n\n" + strings.Repeat("x", 100) + "\n\n"}
+	readers := map[string]func(io.Reader) io.Reader{
+		"plain":    func(r io.Reader) io.Reader { return r },
+		"one byte": iotest.OneByteReader,
+		"half":     iotest.HalfReader,
+	}
+
+	modes := testModes(t)
+	for _, input := range inputs {
+		var original, perByte bytes.Buffer
+		if err := chompLastByte(strings.NewReader(input), &original); err != nil {
+			t.Fatal(err)
+		}
+		if err := chompPerByte(strings.NewReader(input), &perByte, modes[""]); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(perByte.Bytes(), original.Bytes()) {
+			t.Errorf("Default mode, input %q: expected %q like the original chomp, got %q", input, original.Bytes(), perByte.Bytes())
+		}
+	}
+
+	for name, mode := range modes {
+		for _, input := range inputs {
+			var expected bytes.Buffer
+			if err := chompPerByte(strings.NewReader(input), &expected, mode); err != nil {
+				t.Fatal(err)
+			}
+			for _, size := range []int{1, 2, 3, 5, blockSize} {
+				for readerName, wrap := range readers {
+					var got bytes.Buffer
+					if err := chompStream(wrap(strings.NewReader(input)), &got, mode, size); err != nil {
+						t.Fatal(err)
+					}
+					if !bytes.Equal(got.Bytes(), expected.Bytes()) {
+						t.Errorf("Mode %q, size %d, %s reader, input %q: expected %q, got %q",
+							name, size, readerName, input, expected.Bytes(), got.Bytes())
+					}
+				}
+			}
+		}
+	}
+}
+
+// benchmarkInput returns a log file of about 8MB ending with a few newlines
+func benchmarkInput() []byte {
+	var buf bytes.Buffer
+	for i := 0; buf.Len() < 8<<20; i++ {
+		fmt.Fprintf(&buf, "2024-03-01T12:34:56Z INFO request %d served in %dms\n", i, i%250)
+	}
+	buf.WriteString("\n\n")
+	return buf.Bytes()
+}
+
+// BenchmarkChompStream measures the block-based implementation
+func BenchmarkChompStream(b *testing.B) {
+	input := benchmarkInput()
+	for _, name := range []string{"", "-a -crlf", "-chop"} {
+		mode := testModes(b)[name]
+		b.Run("mode="+name, func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+			for i := 0; i < b.N; i++ {
+				if err := chompStream(bytes.NewReader(input), io.Discard, mode, blockSize); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkChompLastByte measures the original per-byte loop, the baseline
+// for the default mode of BenchmarkChompStream
+func BenchmarkChompLastByte(b *testing.B) {
+	input := benchmarkInput()
+	b.SetBytes(int64(len(input)))
+	for i := 0; i < b.N; i++ {
+		if err := chompLastByte(bytes.NewReader(input), io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkChompPerByte measures chompPerByte, which reads one byte at a time
+// but holds bytes back like chompStream
+func BenchmarkChompPerByte(b *testing.B) {
+	input := benchmarkInput()
+	for _, name := range []string{"", "-a -crlf", "-chop"} {
+		mode := testModes(b)[name]
+		b.Run("mode="+name, func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+			for i := 0; i < b.N; i++ {
+				if err := chompPerByte(bytes.NewReader(input), io.Discard, mode); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
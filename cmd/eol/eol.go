@@ -0,0 +1,201 @@
+// eol reads from standard input, or from each file given, and reports how many LF, CRLF and lone CR line endings
+// it holds, or converts every line ending to LF or CRLF. The input is streamed in blocks, so files of any size are
+// handled in constant memory. With -check, only inputs that mix line endings are reported, and the exit code is
+// nonzero when there are any. UTF-16 input that starts with a byte order mark is decoded first, so its line endings
+// are counted, and converted, as UTF-8, unless -raw is given.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jftuga/changecase"
+)
+
+const pgmName string = "eol"
+
+// blockSize is how much input is read at a time
+const blockSize = 64 * 1024
+
+func main() {
+	versionFlag := flag.Bool("version", false, "Display version information")
+	helpFlag := flag.Bool("help", false, "Display help information")
+	toFlag := flag.String("to", "", "Convert every line ending to lf or crlf")
+	checkFlag := flag.Bool("check", false, "Report inputs with mixed line endings and exit with code 1 if there are any")
+	bomFlag := flag.Bool("bom", false, "Start the output of -to with a UTF-8 byte order mark")
+	rawFlag := flag.Bool("raw", false, "Read input as raw bytes, without decoding UTF-16 or removing a byte order mark")
+	formatFlag := flag.String("format", changecase.FormatText, "Output format of the report: text, json, tsv")
+	flag.Parse()
+
+	if *versionFlag || *helpFlag {
+		fmt.Printf("%s, v%s\n", pgmName, changecase.PgmVersion)
+		fmt.Println(changecase.PgmUrl)
+		fmt.Println()
+		fmt.Println("Usage:")
+		fmt.Println("  eol < input.txt")
+		fmt.Println("  eol -check *.go")
+		fmt.Println("  eol -to lf < windows.txt > unix.txt")
+		fmt.Println("  eol -format json a.txt b.txt")
+		fmt.Println()
+		fmt.Println("Reports the line endings of standard input, or of each file given, as counts of LF, CRLF and")
+		fmt.Println("lone CR endings followed by the style: none, lf, crlf, cr or mixed.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fmt.Println("  -to lf|crlf: Convert every line ending, including lone CRs, and write the result to standard output")
+		fmt.Println("  -check: Only report inputs that mix line endings, and exit with code 1 if there are any")
+		fmt.Println("  -bom: Start the output of -to with a UTF-8 byte order mark")
+		fmt.Println("  -raw: Read input as raw bytes, without decoding UTF-16 or removing a UTF-8 byte order mark, for")
+		fmt.Println("        binary input that happens to start with the bytes of a byte order mark")
+		fmt.Println("  -format: Output format of the report: text (default), json, tsv")
+		fmt.Println("Files are processed independently, one after the other; \"-\" reads standard input. Input is decoded")
+		fmt.Println("from UTF-16 when it starts with a byte order mark, unless -raw is given, and -to always writes UTF-8.")
+		return
+	}
+
+	format, err := changecase.ParseFormat(*formatFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	var to string
+	if *toFlag != "" {
+		if to, err = changecase.ParseLineEnding(*toFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if *checkFlag || format != changecase.FormatText {
+			fmt.Fprintln(os.Stderr, "Error: -to cannot be combined with -check or -format")
+			os.Exit(1)
+		}
+	}
+	if *bomFlag && to == "" {
+		fmt.Fprintln(os.Stderr, "Error: -bom only applies to the output of -to")
+		os.Exit(1)
+	}
+
+	if *rawFlag {
+		decodeInput = changecase.RawReader
+	}
+
+	files := flag.Args()
+	names := files
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+
+	// an input that cannot be read is reported, and the rest are still
+	// processed
+	failed, mixed := false, false
+	var records [][]changecase.Field
+	if *bomFlag {
+		fmt.Print(changecase.BOM)
+	}
+	for _, name := range names {
+		var counts changecase.LineEndings
+		if to != "" {
+			counts, err = scanFile(name, os.Stdout, to)
+		} else {
+			counts, err = scanFile(name, io.Discard, "")
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", name, err)
+			failed = true
+			continue
+		}
+		mixed = mixed || counts.Mixed()
+
+		switch {
+		case to != "":
+		case format != changecase.FormatText:
+			if !*checkFlag || counts.Mixed() {
+				records = append(records, reportFields(name, len(files) > 0, counts))
+			}
+		case *checkFlag:
+			if counts.Mixed() {
+				fmt.Printf("%s: mixed line endings (%s)\n", name, describeCounts(counts))
+			}
+		case len(files) > 0:
+			fmt.Printf("%s: %s (%s)\n", name, describeCounts(counts), counts.Style())
+		default:
+			fmt.Printf("%s (%s)\n", describeCounts(counts), counts.Style())
+		}
+	}
+	if len(records) > 0 {
+		if err := changecase.WriteRecords(os.Stdout, format, records); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if failed || (*checkFlag && mixed) {
+		os.Exit(1)
+	}
+}
+
+// decodeInput detects the encoding of input and decodes it to UTF-8; -raw
+// replaces it with changecase.RawReader, which leaves input as it is
+var decodeInput = changecase.DecodeReader
+
+// scanFile streams a file argument, where "-" is standard input, decoded to
+// UTF-8 through a LineEndingWriter that writes to w, and returns its line
+// ending counts
+func scanFile(name string, w io.Writer, to string) (changecase.LineEndings, error) {
+	var f io.Reader = os.Stdin
+	if name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return changecase.LineEndings{}, err
+		}
+		defer file.Close()
+		f = file
+	}
+	r, _, err := decodeInput(f)
+	if err != nil {
+		return changecase.LineEndings{}, err
+	}
+
+	lw := changecase.NewLineEndingWriter(w, to)
+	buf := make([]byte, blockSize)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if _, err := lw.Write(buf[:n]); err != nil {
+				return lw.Counts, fmt.Errorf("writing output: %w", err)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return lw.Counts, err
+		}
+	}
+	if err := lw.Close(); err != nil {
+		return lw.Counts, fmt.Errorf("writing output: %w", err)
+	}
+	return lw.Counts, nil
+}
+
+// describeCounts returns the counts as "LF: 3, CRLF: 1, CR: 0"
+func describeCounts(counts changecase.LineEndings) string {
+	return fmt.Sprintf("LF: %d, CRLF: %d, CR: %d", counts.LF, counts.CRLF, counts.CR)
+}
+
+// reportFields returns the structured report of an input, which starts with
+// its name when files were given
+func reportFields(name string, named bool, counts changecase.LineEndings) []changecase.Field {
+	fields := []changecase.Field{
+		{Name: "lf", Value: counts.LF},
+		{Name: "crlf", Value: counts.CRLF},
+		{Name: "cr", Value: counts.CR},
+		{Name: "style", Value: counts.Style()},
+		{Name: "mixed", Value: counts.Mixed()},
+	}
+	if named {
+		fields = append([]changecase.Field{{Name: "file", Value: name}}, fields...)
+	}
+	return fields
+}
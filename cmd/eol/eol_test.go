@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEOL(t *testing.T) {
+	dir := t.TempDir()
+	mixed := filepath.Join(dir, "mixed.txt")
+	unix := filepath.Join(dir, "unix.txt")
+	if err := os.WriteFile(mixed, []byte("a\r\nb\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(unix, []byte("a\nb\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args     []string
+		input    string
+		expected string
+		exitCode int
+	}{
+		{nil, "a\nb\n", "LF: 2, CRLF: 0, CR: 0 (lf)\n", 0},
+		{nil, "a\r\nb\r\nc", "LF: 0, CRLF: 2, CR: 0 (crlf)\n", 0},
+		{nil, "a\r\nb\nc\r", "LF: 1, CRLF: 1, CR: 1 (mixed)\n", 0},
+		{nil, "", "LF: 0, CRLF: 0, CR: 0 (none)\n", 0},
+		{[]string{unix, mixed}, "", unix + ": LF: 2, CRLF: 0, CR: 0 (lf)\n" + mixed + ": LF: 1, CRLF: 1, CR: 0 (mixed)\n", 0},
+		{[]string{"-check", unix, mixed}, "", mixed + ": mixed line endings (LF: 1, CRLF: 1, CR: 0)\n", 1},
+		{[]string{"-check", unix}, "", "", 0},
+		{[]string{"-check"}, "a\rb\n", "-: mixed line endings (LF: 1, CRLF: 0, CR: 1)\n", 1},
+		{[]string{"-to", "lf"}, "a\r\nb\rc\n", "a\nb\nc\n", 0},
+		{[]string{"-to", "crlf"}, "a\r\nb\rc\nd", "a\r\nb\r\nc\r\nd", 0},
+		{[]string{"-to", "crlf", unix, "-"}, "x\n", "a\r\nb\r\nx\r\n", 0},
+		{[]string{"-format", "json"}, "a\r\n", `{"lf":0,"crlf":1,"cr":0,"style":"crlf","mixed":false}` + "\n", 0},
+		{nil, "\xFF\xFEa\x00\r\x00\n\x00b\x00\n\x00", "LF: 1, CRLF: 1, CR: 0 (mixed)\n", 0},
+		{[]string{"-to", "crlf"}, "\xEF\xBB\xBFa\nb", "a\r\nb", 0},
+		{[]string{"-to", "lf", "-bom"}, "\xFE\xFF\x00a\x00\r\x00\n", "\xEF\xBB\xBFa\n", 0},
+		{[]string{"-raw"}, "\xFF\xFE\r\n\x00", "LF: 0, CRLF: 1, CR: 0 (crlf)\n", 0},
+		{[]string{"-raw", "-to", "lf"}, "\xEF\xBB\xBFa\r\n", "\xEF\xBB\xBFa\n", 0},
+		{[]string{"-format", "tsv", unix}, "", "file\tlf\tcrlf\tcr\tstyle\tmixed\n" + unix + "\t2\t0\t0\tlf\tfalse\n", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			output, exitCode := runEOL(t, test.input, test.args...)
+			if exitCode != test.exitCode {
+				t.Errorf("Expected exit code %d, got %d", test.exitCode, exitCode)
+			}
+			if output != test.expected {
+				t.Errorf("Input: %q\nExpected: %q\nGot: %q", test.input, test.expected, output)
+			}
+		})
+	}
+
+	// Test with an unsupported line ending, options that cannot be combined and a missing file
+	for _, args := range [][]string{{"-to", "cr"}, {"-to", "lf", "-check"}, {"-bom"}, {"-to", "lf", "-format", "json"}, {filepath.Join(dir, "missing.txt")}} {
+		t.Run("error "+strings.Join(args, " "), func(t *testing.T) {
+			if _, exitCode := runEOL(t, "", args...); exitCode == 0 {
+				t.Errorf("Expected an error, got none")
+			}
+		})
+	}
+}
+
+// runEOL runs the eol program with the given input and arguments, and
+// returns its output and exit code
+func runEOL(t *testing.T, input string, args ...string) (string, int) {
+	cmd := exec.Command("go", append([]string{"run", "eol.go"}, args...)...)
+	var stdout bytes.Buffer
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = &stdout
+	err := cmd.Run()
+	if exiterr, ok := err.(*exec.ExitError); ok {
+		return stdout.String(), exiterr.ExitCode()
+	}
+	if err != nil {
+		t.Fatalf("Error running eol: %v", err)
+	}
+	return stdout.String(), 0
+}
@@ -6,34 +6,255 @@ whether two strings match exactly. If they don't match, it reports the position
 of the first difference using 1-based indexing.
 
 Usage:
-  eq [-i] [-q] [-v] [--version] [string1 string2]
+  eq [-a] [-fuzzy threshold [-algo name]] [-i] [-w|-b] [-Z] [-eol] [-confusables] [-skeleton]
+     [-ignore regex ...] [-mask name ...] [-unit name] [-q] [-v] [--version] [string1 string2]
+  eq -f [-i] [-q] [-v] file1|- file2|-
+  eq -diff format [-width N] [-f] [-i] [-w|-b] [-Z] [-eol] [-skeleton] [-q] [-v] string1|file1 string2|file2
+  eq [-majority] [-n] [-i] [-unit name] [-q] [-v] [string1 string2 string3 ...]
+  eq -numeric [-tolerance N] [-rel-tolerance N] [-i] [-unit name] [-q] [-v] [string1 string2]
+  eq -batch manifest|- [-batch-format name] [-parallel N] [-i] [-w|-b] [-Z] [-eol] [-unit name] [-q] [-v]
+  eq -json [-tolerance N] [-rel-tolerance N] [-unordered] [-f] [-q] [-v] [json1|file1 json2|file2]
+  eq -secret [source1 source2]
+  eq -match mode [-i] [-w|-b] [-Z] [-eol] [-skeleton] [-q] [-v] string pattern
+  eq -format json|tsv [-i] [-w|-b] [-Z] [-eol] [-skeleton] [-ignore regex ...] [-mask name ...] [-q] [string1 string2]
 
 Options:
+  -a  Report every difference, the edit distance and a similarity percentage
+  -f  Compare the contents of two files instead of two strings
+  -fuzzy threshold  Succeed when the similarity score (0.0 to 1.0) is at least threshold
+  -algo name  Similarity algorithm for -fuzzy: levenshtein (default), damerau,
+              jaro or jaro-winkler
   -i  Perform case-insensitive comparison
+  -majority  With three or more strings, compare against the most common value
+             instead of the first string
+  -n  Read every line from STDIN instead of just two
+  -Z  Ignore whitespace at the end of each line
+  -b  Treat each run of whitespace as a single space
+  -w  Ignore all whitespace
+  -eol  Treat CRLF, CR and LF line endings as equal
+  -confusables  Explain whether the first difference is a pair of look-alike
+                characters (homoglyphs), such as Cyrillic "а" and Latin "a"
+  -skeleton  Compare the UTS #39 "skeletons" of the strings, so that look-alike
+             characters are treated as equal
+  -ignore regex  Replace text matching regex (RE2 syntax) with "<ignored>"
+                before comparing; may be given more than once
+  -mask names  Replace built-in patterns with a placeholder such as "<uuid>"
+               before comparing: uuid, iso8601 (dates and timestamps),
+               hexaddr (0x...) and ipv4; comma separated or repeated
   -q  Quiet mode (no output, only exit code)
+  -match mode  Match the second argument as a pattern against the first:
+               prefix, suffix, contains, glob (shell wildcards * ? [a-z],
+               matched against the whole string) or regex (RE2 syntax)
+  -diff format  Compare multi-line input line by line and print a unified
+                (like diff -u) or side-by-side (like sdiff) diff of two
+                arguments, or of two files with -f, one of which may be "-"
+                to read STDIN
+  -width N  Total width of -diff side-by-side output; defaults to $COLUMNS,
+            or 130 when it is not set
+  -json  Parse both inputs as JSON and compare them structurally, so key order
+         and formatting do not matter; see "JSON mode" below
+  -batch manifest  Compare every expected/actual pair listed in a manifest
+                   file, or on STDIN with "-"; see "Batch mode" below
+  -batch-format name  Format of the -batch manifest: auto (default), tsv,
+                      ndjson or pairs
+  -parallel N  Number of -batch pairs compared at the same time (default 1)
+  -numeric  Split both strings into tokens (numbers, words, whitespace and
+            punctuation) and compare numbers by value, within the tolerance,
+            and everything else exactly; see "Numeric mode" below
+  -tolerance N  With -json or -numeric, numbers that differ by at most N are
+                equal
+  -rel-tolerance N  With -json or -numeric, numbers that differ by at most N
+                    times the larger magnitude are equal; when both
+                    tolerances are given, meeting either one is enough
+  -unordered  With -json, arrays are equal when they hold the same elements
+              in any order
+  -secret  Compare two secrets in constant time; see "Secret mode" below
+  -unit name  Unit used to print mismatch positions: rune (default), byte,
+              column (display column, counting wide characters as two and
+              expanding tabs to every 8th column) or line:col (line number and
+              rune column within that line); all units are 1-based. Not
+              available with -f, -a, -fuzzy, -match or -diff, which report
+              positions of their own or none
   -v  Verbose mode (shows detailed comparison with context)
+  -color mode  Color verbose output: auto (default; only when stdout is a
+               terminal and NO_COLOR is unset or empty), always or never
+  -format name  Output format of a two-string comparison: text (default),
+                json or tsv; see "Structured output" below
   --version  Display version information
 
 Input:
   - If two command line arguments are provided, they will be compared
-  - If no arguments are provided, two lines will be read from STDIN
+  - If no arguments are provided, two lines will be read from STDIN; lines
+    may be of any length. Unless the output needs both lines in full (-v, -a,
+    -n, -fuzzy, ...), the second line is compared as it is read, but the
+    first one is still held in memory; for constant memory, use -f with a
+    file and "-" for STDIN
+  - If more than two arguments are provided (or -n is given with STDIN),
+    every string is compared against the first one, or the majority value
+  - -Z, -b, -w, -eol, -skeleton, -ignore and -mask apply to string
+    comparisons (and to -f only with -diff); positions are still reported
+    relative to the original, unmodified input
+  - Masks are applied in the order -mask then -ignore, before any whitespace
+    option; a match that overlaps text already masked is left alone
+  - With -f, the two arguments are file names whose contents are streamed
+    and compared rune by rune in constant memory, so files (and lines) of
+    any size can be compared; one of them may be "-" to read STDIN, as in
+    "generate | eq -f - golden.txt"
+  - STDIN, files and manifests are decoded from UTF-16 when they start with
+    a byte order mark, and a UTF-8 byte order mark is removed, so a file
+    saved by a Windows editor compares equal to the same text without one.
+    Secrets are always compared exactly as given
 
 Output:
   - Standard mode: Prints the position of the first difference (1-based),
     or "0" if strings match exactly
   - Verbose mode: Shows detailed information about the match/mismatch
-    with context around the differing position
+    with context around the differing position; when whitespace is ignored
+    and the positions in the two strings differ, both are shown; the
+    position is also given in every -unit, plus the 0-based byte offset.
+    Control and invisible characters (tab, CR, NBSP, zero-width space, ...)
+    are shown as escapes such as \t or \u00a0, a caret line points at the
+    differing rune and the code points of both differing runes are listed.
+    With -ignore or -mask, every masked region of each string is listed first
   - Quiet mode: No output, only exit code
+  - All differences mode: Lists every differing range in the style of
+    diff's "normal" format (2,3c2 / 5a6 / 7d6) using 1-based rune positions,
+    followed by the edit distance (inserted plus deleted runes) and the
+    similarity percentage, as computed by a Myers LCS diff
+  - Multiple strings: Prints "0" if all strings match, otherwise one line per
+    deviating string with the position where it differs from the reference;
+    verbose mode shows the detailed comparison for each of them
+  - File mode: Prints the line, the rune-based column and the 0-based byte
+    offset of the first difference, or "0" if the files match; verbose mode
+    also shows the lines before, at and after the difference in each file,
+    clipped to 40 characters either side of the difference with "…"
+  - Match mode: Prints "0" if the pattern matches, "1" otherwise; verbose
+    mode shows the 1-based positions of the matched span and brackets it
+  - Confusables: After the usual output, names the two differing characters,
+    says whether they are confusable, and whether the strings only differ
+    by confusable characters. The table of confusables is embedded from
+    confusables.txt at the root of the repository
+  - Diff mode: Prints a unified diff (with 3 lines of context) or every line
+    side by side, marking changed lines with "|", removed lines with "<" and
+    added lines with ">". -i, -Z, -b, -w, -eol and -skeleton decide which
+    lines are equal, but the original lines are printed. Within each changed
+    line, the differing runes are highlighted when colors are enabled; in
+    side-by-side output they are bracketed when colors are disabled. Verbose
+    mode adds a count of removed and added lines
+  - Fuzzy mode: Prints the similarity score; verbose mode also shows the
+    algorithm, edit distance (when applicable) and threshold
+
+Structured output:
+  - With -format json, a two-string comparison prints a single JSON object
+    on one line; with -format tsv, a header row and a row of values, with
+    nested fields named like "position1.byte" and tabs, line breaks and
+    backslashes escaped as \t, \n, \r and \\
+  - The fields are always present and in this order: match (true or false),
+    kind (none, rune, first-ended or second-ended), position1 and position2
+    (rune, byte, offset, column, line and line_column of the first
+    difference in each string, all 0 when the strings match) and context1
+    and context2 (the runes before, at and after the difference; "at" is
+    empty where a string has ended)
+  - The exit code is the same as for text output
+  - The other modes, -v and more than two strings only support text output
+
+Batch mode:
+  - tsv: one pair per line as "expected<TAB>actual", with an optional third
+    name field; \t, \n, \r and \\ escapes are decoded in every field
+  - ndjson: one JSON object per line with "expected", "actual" and an
+    optional "name"
+  - pairs: every two consecutive lines are the expected and actual strings
+  - auto picks tsv or ndjson from a .tsv, .ndjson or .jsonl extension, or
+    else from the first non-empty line: ndjson when it starts with "{", tsv
+    when it holds a tab and pairs otherwise
+  - Prints a table with the result of every pair, the position (in -unit) of
+    the first difference of each failing pair and its name (or its line
+    numbers), followed by a summary; verbose mode then shows the detailed
+    comparison of every failing pair
+  - The comparison honors -i, -Z, -b, -w, -eol, -skeleton, -ignore and -mask;
+    -batch cannot be combined with the other modes
+
+Numeric mode:
+  - Numbers are decimal integers or floats with an optional sign and exponent,
+    such as 42, -1.5 or 6.02e23; without a tolerance they must be exactly
+    equal in value, so "1.0" and "1" match but 9007199254740993 and
+    9007199254740992 do not
+  - Runs of digits with two or more dots, such as versions (1.2.3) and IPv4
+    addresses, are single tokens compared as text, and a "." right after a
+    digit or another "." does not start a number, so "v1.5" and "v1.50"
+    differ
+  - Prints "0" if the strings match, otherwise the position (in -unit) of the
+    start of the first differing token in the first string; verbose mode shows
+    both tokens, their numeric difference and where they are in each string
+  - -numeric can only be combined with -tolerance, -rel-tolerance, -i, -unit,
+    -q, -v and -color
+
+JSON mode:
+  - The documents are two arguments, two files with -f, or two consecutive
+    documents on STDIN, each of which may span several lines
+  - Objects are equal when they have the same keys with equal values, in any
+    order; numbers are compared by value, so 1, 1.0 and 1e0 are equal, and
+    large integers are compared exactly
+  - Prints "0" if the documents are equal, otherwise the path of the first
+    difference in jq syntax, such as .items[3].name (keys are visited in
+    sorted order); verbose mode also says what differs and shows both values
+  - -json can only be combined with -tolerance, -rel-tolerance, -unordered,
+    -f, -q, -v and -color
+
+Secret mode:
+  - Each secret comes from a source rather than from the command line, so it
+    never shows up in ps: "env:NAME" (environment variable), "file:PATH"
+    (file contents) or "-" (the next line of STDIN); without sources, two
+    lines are read from STDIN. A single trailing newline is removed from file
+    and STDIN values
+  - The secrets are hashed with SHA-256 and the digests are compared with
+    crypto/subtle, so the time taken does not depend on where they differ
+  - Nothing is printed, whatever the outcome; the result is the exit code
+  - -secret cannot be combined with any option other than -q
 
 Exit Codes:
   - 0 if strings match exactly
-  - N (position number) if strings differ at position N
+  - N (position number) if strings differ at position N; this is always
+    the rune position, whatever -unit is used for the output, capped at 255
+    because larger exit codes wrap around (256 would read as 0)
+  - Fuzzy mode: 0 if the score meets the threshold, 1 otherwise
+  - File mode: 0 if the files match, 1 otherwise
+  - Multiple strings: the number of strings that deviate from the reference,
+    capped at 255
+  - Secret mode: 0 if the secrets match, 1 otherwise (including errors)
+  - Match mode: 0 if the pattern matches, 1 otherwise
+  - Diff mode: 0 if no lines differ, 1 otherwise
+  - Batch mode: 0 if every pair matches, 1 otherwise (including errors)
+  - Numeric mode: 0 if the strings match, otherwise the rune position of the
+    first differing token in the first string, capped at 255
+  - JSON mode: 0 if the documents are equal, 1 otherwise (including errors)
 
 Examples:
   eq "hello" "hello"    # Will output "0" and exit with code 0
   eq "hello" "hallo"    # Will output "3" and exit with code 3
   eq -i "Hello" "hello" # Will output "0" and exit with code 0 (case-insensitive)
   eq -v "abc" "abx"     # Will show detailed difference at position 3
+  eq -format json "abc" "abx" # Will output {"match":false,"kind":"rune",...}
+  eq -unit byte "café!" "café?" # Will output "6" and exit with code 5
+  eq -a "kitten" "sitting" # Will list each differing range
+  eq -fuzzy 0.9 "Jon Smith" "John Smith" # Will output "0.9000" and exit with code 0
+  eq 1.4.0 1.4.0 1.4.1  # Will output "string 3 differs at position 5"
+  eq -Z -eol "a b \r\n" "a b\n" # Will output "0" and exit with code 0
+  eq -confusables "pаypal" "paypal" # Will explain the Cyrillic "а" at position 2
+  eq -skeleton "pаypal" "paypal" # Will output "0" and exit with code 0
+  eq -mask uuid,iso8601 "id=$ID1 at $T1" "id=$ID2 at $T2" # Will output "0" for any ids and times
+  eq -ignore 'pid=[0-9]+' "pid=12 ok" "pid=345 ok" # Will output "0" and exit with code 0
+  eq -match prefix "v1.4.0" "v1."  # Will output "0" and exit with code 0
+  eq -v -match regex "build 1234 ok" '[0-9]+' # Will show the match at positions 7-10
+  eq -batch golden.tsv -parallel 8 -mask uuid # Will print a table of results
+  eq -numeric "t=1.0000001s" "t=1.0s" # Will output "3" and exit with code 3
+  eq -numeric -tolerance 1e-6 "t=1.0000001s" "t=1.0s" # Will output "0" and exit with code 0
+  eq -json '{"a":1,"b":2}' '{"b":2,"a":1.0}' # Will output "0" and exit with code 0
+  eq -json -f -unordered expected.json actual.json # Will output e.g. ".items[3].name"
+  eq -secret env:TOKEN file:/run/secrets/token # Exit code 0 if they match
+  eq -diff unified -f old.conf new.conf # Will print a unified diff
+  eq -diff side-by-side -width 80 "$(cat a)" "$(cat b)" # Will print both side by side
+  eq -f a.txt b.txt     # Will output e.g. "line 3, column 5, byte offset 27"
   echo -e "str1\nstr2" | eq  # Read strings from stdin
 */
 
@@ -41,7 +262,7 @@ Examples:
 package main
 
 import (
-	"bufio"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -52,164 +273,124 @@ import (
 
 const pgmName string = "eq"
 
-// processInput handles command line arguments and stdin to get the strings to compare.
-// It returns the two strings to compare.
-func processInput() (string, string) {
-	// Check if we have non-flag arguments
-	args := flag.Args()
+// listFlag collects the values of a flag that may be given more than once
+type listFlag []string
 
-	// If two arguments are provided, use them as the strings to compare
-	if len(args) == 2 {
-		return args[0], args[1]
-	}
-
-	// If no arguments are provided, read from stdin
-	if len(args) == 0 {
-		scanner := bufio.NewScanner(os.Stdin)
-
-		// Read first line
-		if !scanner.Scan() {
-			fmt.Fprintln(os.Stderr, "Error reading first line from stdin")
-			os.Exit(1)
-		}
-		str1 := scanner.Text()
-
-		// Read second line
-		if !scanner.Scan() {
-			fmt.Fprintln(os.Stderr, "Error reading second line from stdin")
-			os.Exit(1)
-		}
-		str2 := scanner.Text()
-
-		return str1, str2
-	}
-
-	// Invalid number of arguments
-	fmt.Fprintln(os.Stderr, "Invalid number of arguments")
-	flag.Usage()
-	os.Exit(1)
-	return "", "" // This will never execute, but needed for compilation
+func (list *listFlag) String() string {
+	return strings.Join(*list, ",")
 }
 
-// compareStrings compares two strings and returns:
-// - 0 if strings match
-// - position (1-based) of first mismatch otherwise
-func compareStrings(str1, str2 string, caseInsensitive bool) int {
-	// For case-insensitive comparison, convert to lowercase
-	if caseInsensitive {
-		str1 = strings.ToLower(str1)
-		str2 = strings.ToLower(str2)
-	}
-
-	// Get the runes for proper Unicode handling
-	runes1 := []rune(str1)
-	runes2 := []rune(str2)
-
-	// Compare the strings character by character
-	for i := 0; i < len(runes1) && i < len(runes2); i++ {
-		if runes1[i] != runes2[i] {
-			return i + 1 // Return 1-based index of mismatch
-		}
-	}
-
-	// If we got here, either strings match or one is a prefix of the other
-	if len(runes1) != len(runes2) {
-		return min(len(runes1), len(runes2)) + 1
-	}
-
-	// Strings match
-	return 0
+func (list *listFlag) Set(value string) error {
+	*list = append(*list, value)
+	return nil
 }
 
-// displayVerboseComparison shows a detailed comparison of the two strings
-func displayVerboseComparison(str1, str2 string, position int) {
-	if position == 0 {
-		fmt.Println("Strings match exactly")
-		return
-	}
-
-	fmt.Printf("Strings differ at position %d\n", position)
-
-	// Display the characters at the differing position
-	runes1 := []rune(str1)
-	runes2 := []rune(str2)
-
-	// Show the difference with context
-	pos := position - 1 // Convert to 0-based
-	fmt.Println("Difference:")
-
-	// Calculate safe ranges for context display
-	startPos := max(0, pos-5)
-	endPos1 := min(len(runes1), pos+6)
-	endPos2 := min(len(runes2), pos+6)
-
-	// Display the context with the difference highlighted
-	// Handle the differing character for string 1
-	var diffChar1, endChar1 string
-	if pos < len(runes1) {
-		diffChar1 = string(runes1[pos : pos+1])
-	} else {
-		diffChar1 = "END"
-	}
-	if pos+1 < len(runes1) {
-		endChar1 = string(runes1[pos+1 : endPos1])
-	} else {
-		endChar1 = ""
-	}
-
-	// Handle the differing character for string 2
-	var diffChar2, endChar2 string
-	if pos < len(runes2) {
-		diffChar2 = string(runes2[pos : pos+1])
-	} else {
-		diffChar2 = "END"
-	}
-	if pos+1 < len(runes2) {
-		endChar2 = string(runes2[pos+1 : endPos2])
-	} else {
-		endChar2 = ""
-	}
-
-	fmt.Printf("String 1: %s[%s]%s\n",
-		string(runes1[startPos:min(pos, len(runes1))]),
-		diffChar1,
-		endChar1)
-
-	fmt.Printf("String 2: %s[%s]%s\n",
-		string(runes2[startPos:min(pos, len(runes2))]),
-		diffChar2,
-		endChar2)
-}
-
-// min returns the minimum of two integers
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
+// flagGiven reports whether the named flag was given on the command line,
+// whatever its value
+func flagGiven(name string) bool {
+	given := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			given = true
+		}
+	})
+	return given
 }
 
-// max returns the maximum of two integers
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
+// onlyFlags reports whether every flag given on the command line is one of names
+func onlyFlags(names ...string) bool {
+	only := true
+	flag.Visit(func(f *flag.Flag) {
+		for _, name := range names {
+			if f.Name == name {
+				return
+			}
+		}
+		only = false
+	})
+	return only
 }
 
 func main() {
 	// Define command-line flags
+	allDifferencesFlag := flag.Bool("a", false, "Report every difference, the edit distance and similarity")
+	fileModeFlag := flag.Bool("f", false, "Compare the contents of two files")
+	fuzzyFlag := flag.Float64("fuzzy", 0, "Succeed when the similarity score is at least this threshold (0.0 to 1.0)")
+	algoFlag := flag.String("algo", string(changecase.AlgoLevenshtein), "Similarity algorithm for -fuzzy: levenshtein, damerau, jaro, jaro-winkler")
 	caseInsensitiveFlag := flag.Bool("i", false, "Perform case-insensitive comparison")
+	majorityFlag := flag.Bool("majority", false, "Compare against the most common value instead of the first string")
+	allLinesFlag := flag.Bool("n", false, "Read every line from stdin instead of just two")
+	ignoreTrailingFlag := flag.Bool("Z", false, "Ignore whitespace at the end of each line")
+	collapseFlag := flag.Bool("b", false, "Treat each run of whitespace as a single space")
+	ignoreAllFlag := flag.Bool("w", false, "Ignore all whitespace")
+	eolFlag := flag.Bool("eol", false, "Treat CRLF, CR and LF line endings as equal")
+	matchFlag := flag.String("match", "", "Match string2 as a pattern against string1: prefix, suffix, contains, glob, regex")
+	confusablesFlag := flag.Bool("confusables", false, "Explain whether the first difference is a pair of look-alike characters")
+	skeletonFlag := flag.Bool("skeleton", false, "Treat look-alike (confusable) characters as equal")
+	var ignoreFlags, maskFlags listFlag
+	flag.Var(&ignoreFlags, "ignore", "Mask text matching this regexp before comparing (repeatable)")
+	flag.Var(&maskFlags, "mask", "Mask built-in patterns before comparing: uuid, iso8601, hexaddr, ipv4 (repeatable or comma separated)")
+	batchFlag := flag.String("batch", "", "Compare every expected/actual pair of a TSV or NDJSON manifest, or of line pairs (\"-\" for stdin)")
+	batchFormatFlag := flag.String("batch-format", "auto", "Format of the -batch manifest: auto, tsv, ndjson, pairs")
+	parallelFlag := flag.Int("parallel", 1, "Number of -batch pairs to compare at the same time")
+	jsonFlag := flag.Bool("json", false, "Compare two JSON documents structurally, reporting the first differing path")
+	numericFlag := flag.Bool("numeric", false, "Compare numbers within a tolerance and everything else exactly, reporting the first differing token")
+	toleranceFlag := flag.Float64("tolerance", 0, "With -json or -numeric, numbers differing by at most this much are equal")
+	relToleranceFlag := flag.Float64("rel-tolerance", 0, "With -json or -numeric, numbers differing by at most this fraction of the larger one are equal")
+	unorderedFlag := flag.Bool("unordered", false, "With -json, arrays are equal when they hold the same elements in any order")
+	diffFlag := flag.String("diff", "", "Show a line diff of multi-line input: unified, side-by-side")
+	widthFlag := flag.Int("width", 0, "Total width of -diff side-by-side output (default $COLUMNS or 130)")
+	secretFlag := flag.Bool("secret", false, "Compare two secrets in constant time, reporting only the exit code")
+	unitFlag := flag.String("unit", "rune", "Unit for mismatch positions: rune, byte, column, line:col")
+	colorFlag := flag.String("color", "auto", "Color verbose output: auto, always, never")
+	formatFlag := flag.String("format", changecase.FormatText, "Output format of a two-string comparison: text, json, tsv")
 	quietModeFlag := flag.Bool("q", false, "Quiet mode (no output, only exit code)")
 	verboseModeFlag := flag.Bool("v", false, "Verbose mode (shows detailed comparison)")
 	versionFlag := flag.Bool("version", false, "Display version information")
 
 	// Add custom usage message
 	flag.Usage = func() {
-		fmt.Fprintln(os.Stderr, "Usage: eq [-i] [-q] [-v] [--version] [string1 string2]")
+		fmt.Fprintln(os.Stderr, "Usage: eq [-a] [-fuzzy threshold [-algo name]] [-i] [-w|-b] [-Z] [-eol] [-unit name] [-q] [-v] [--version] [string1 string2]")
+		fmt.Fprintln(os.Stderr, "       eq -format json|tsv [-i] [-w|-b] [-Z] [-eol] [-skeleton] [-ignore REGEX] [-mask names] [-q] [string1 string2]")
+		fmt.Fprintln(os.Stderr, "       eq -f [-i] [-q] [-v] file1|- file2|-")
+		fmt.Fprintln(os.Stderr, "       eq -diff unified|side-by-side [-width N] [-f] [-i] [-w|-b] [-Z] [-eol] [-q] [-v] string1|file1 string2|file2")
+		fmt.Fprintln(os.Stderr, "       eq -batch manifest|- [-batch-format name] [-parallel N] [-i] [-w|-b] [-Z] [-eol] [-unit name] [-q] [-v]")
+		fmt.Fprintln(os.Stderr, "       eq -numeric [-tolerance N] [-rel-tolerance N] [-i] [-unit name] [-q] [-v] [string1 string2]")
+		fmt.Fprintln(os.Stderr, "       eq -json [-tolerance N] [-rel-tolerance N] [-unordered] [-f] [-q] [-v] [json1|file1 json2|file2]")
+		fmt.Fprintln(os.Stderr, "       eq -secret [env:NAME|file:PATH|- env:NAME|file:PATH|-]")
+		fmt.Fprintln(os.Stderr, "       eq [-majority] [-n] [-i] [-unit name] [-q] [-v] [string1 string2 string3 ...]")
+		fmt.Fprintln(os.Stderr, "  -a: Report every difference, the edit distance and similarity")
+		fmt.Fprintln(os.Stderr, "  -f: Compare the contents of two files (\"-\" for stdin)")
+		fmt.Fprintln(os.Stderr, "  -fuzzy: Succeed when the similarity score is at least this threshold (0.0 to 1.0)")
+		fmt.Fprintln(os.Stderr, "  -algo: Similarity algorithm for -fuzzy: levenshtein, damerau, jaro, jaro-winkler")
 		fmt.Fprintln(os.Stderr, "  -i: Perform case-insensitive comparison")
+		fmt.Fprintln(os.Stderr, "  -majority: Compare against the most common value instead of the first string")
+		fmt.Fprintln(os.Stderr, "  -n: Read every line from stdin instead of just two")
+		fmt.Fprintln(os.Stderr, "  -Z: Ignore whitespace at the end of each line")
+		fmt.Fprintln(os.Stderr, "  -b: Treat each run of whitespace as a single space")
+		fmt.Fprintln(os.Stderr, "  -w: Ignore all whitespace")
+		fmt.Fprintln(os.Stderr, "  -eol: Treat CRLF, CR and LF line endings as equal")
+		fmt.Fprintln(os.Stderr, "  -match: Match string2 as a pattern against string1: prefix, suffix, contains, glob, regex")
+		fmt.Fprintln(os.Stderr, "  -confusables: Explain whether the first difference is a pair of look-alike characters")
+		fmt.Fprintln(os.Stderr, "  -skeleton: Treat look-alike (confusable) characters as equal")
+		fmt.Fprintln(os.Stderr, "  -ignore: Mask text matching this regexp before comparing (repeatable)")
+		fmt.Fprintln(os.Stderr, "  -mask: Mask built-in patterns before comparing: uuid, iso8601, hexaddr, ipv4 (repeatable or comma separated)")
+		fmt.Fprintln(os.Stderr, "  -batch: Compare every expected/actual pair of a TSV or NDJSON manifest, or of line pairs (\"-\" for stdin)")
+		fmt.Fprintln(os.Stderr, "  -batch-format: Format of the -batch manifest: auto (default), tsv, ndjson, pairs")
+		fmt.Fprintln(os.Stderr, "  -parallel: Number of -batch pairs to compare at the same time")
+		fmt.Fprintln(os.Stderr, "  -json: Compare two JSON documents structurally, reporting the first differing path")
+		fmt.Fprintln(os.Stderr, "  -numeric: Compare numbers within a tolerance and everything else exactly, reporting the first differing token")
+		fmt.Fprintln(os.Stderr, "  -tolerance: With -json or -numeric, numbers differing by at most this much are equal")
+		fmt.Fprintln(os.Stderr, "  -rel-tolerance: With -json or -numeric, numbers differing by at most this fraction of the larger one are equal")
+		fmt.Fprintln(os.Stderr, "  -unordered: With -json, arrays are equal when they hold the same elements in any order")
+		fmt.Fprintln(os.Stderr, "  -diff: Show a line diff of multi-line input: unified, side-by-side")
+		fmt.Fprintln(os.Stderr, "  -width: Total width of -diff side-by-side output (default $COLUMNS or 130)")
+		fmt.Fprintln(os.Stderr, "  -secret: Compare two secrets in constant time, reporting only the exit code")
 		fmt.Fprintln(os.Stderr, "  -q: Quiet mode (no output, only exit code)")
+		fmt.Fprintln(os.Stderr, "  -unit: Unit for mismatch positions: rune (default), byte, column, line:col")
 		fmt.Fprintln(os.Stderr, "  -v: Verbose mode (shows detailed comparison)")
+		fmt.Fprintln(os.Stderr, "  -color: Color verbose output: auto (default), always, never")
+		fmt.Fprintln(os.Stderr, "  -format: Output format of a two-string comparison: text (default), json, tsv")
 		fmt.Fprintln(os.Stderr, "  --version: Display version information")
 		fmt.Fprintln(os.Stderr, "  If strings are not provided, reads two lines from stdin")
 	}
@@ -224,26 +405,181 @@ func main() {
 		os.Exit(0)
 	}
 
-	// Get the strings to compare
-	str1, str2 := processInput()
+	// Validate the fuzzy matching options before reading any input
+	algo, err := changecase.ParseAlgorithm(*algoFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fuzzy := flagGiven("fuzzy")
+	if fuzzy && (*fuzzyFlag < 0 || *fuzzyFlag > 1) {
+		fmt.Fprintln(os.Stderr, "Error: -fuzzy threshold must be between 0.0 and 1.0")
+		os.Exit(1)
+	}
+	if !fuzzy && flagGiven("algo") {
+		fmt.Fprintln(os.Stderr, "Error: -algo requires -fuzzy")
+		os.Exit(1)
+	}
+	unit, err := changecase.ParseUnit(*unitFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	format, err := changecase.ParseFormat(*formatFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	colors, err := changecase.NewPalette(*colorFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	var matchMode changecase.MatchMode
+	if *matchFlag != "" {
+		if matchMode, err = changecase.ParseMatchMode(*matchFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *diffFlag != "" && *diffFlag != changecase.DiffUnified && *diffFlag != changecase.DiffSideBySide {
+		fmt.Fprintf(os.Stderr, "Error: unknown diff format %q (want unified or side-by-side)\n", *diffFlag)
+		os.Exit(1)
+	}
+	if *diffFlag != "" && (*allDifferencesFlag || fuzzy || matchMode != "" || *confusablesFlag || *majorityFlag || *allLinesFlag) {
+		fmt.Fprintln(os.Stderr, "Error: -diff cannot be combined with -a, -fuzzy, -match, -confusables, -majority or -n")
+		os.Exit(1)
+	}
+	if format != changecase.FormatText && (*fileModeFlag || *batchFlag != "" || *jsonFlag || *numericFlag || *diffFlag != "" ||
+		*allDifferencesFlag || fuzzy || matchMode != "" || *confusablesFlag || *majorityFlag || *allLinesFlag || *verboseModeFlag) {
+		fmt.Fprintln(os.Stderr, "Error: -format json and tsv only apply to comparing two strings, with -i, -w, -b, -Z, -eol, -skeleton, -ignore, -mask and -q")
+		os.Exit(1)
+	}
 
-	// Compare the strings and get the result
-	position := compareStrings(str1, str2, *caseInsensitiveFlag)
+	if flagGiven("unit") && (*fileModeFlag || *allDifferencesFlag || fuzzy || matchMode != "" || *diffFlag != "") {
+		fmt.Fprintln(os.Stderr, "Error: -unit cannot be combined with -f, -a, -fuzzy, -match or -diff, which report positions of their own or none")
+		os.Exit(1)
+	}
 
-	// Handle output based on mode
-	if !*quietModeFlag {
-		if *verboseModeFlag {
-			displayVerboseComparison(str1, str2, position)
-		} else {
-			// Standard output - just position
-			fmt.Println(position)
+	opts := changecase.Options{
+		CaseInsensitive: *caseInsensitiveFlag,
+		Whitespace: changecase.WhitespaceOptions{
+			IgnoreTrailing: *ignoreTrailingFlag,
+			Collapse:       *collapseFlag,
+			IgnoreAll:      *ignoreAllFlag,
+			NormalizeEOL:   *eolFlag,
+		},
+		Skeleton: *skeletonFlag,
+	}
+	for _, names := range maskFlags {
+		for _, name := range strings.Split(names, ",") {
+			mask, err := changecase.ParseMask(strings.TrimSpace(name))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			opts.Masks = append(opts.Masks, mask)
+		}
+	}
+	for _, expr := range ignoreFlags {
+		mask, err := changecase.NewMask(expr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -ignore pattern: %v\n", err)
+			os.Exit(1)
 		}
+		opts.Masks = append(opts.Masks, mask)
 	}
 
-	// Exit with the appropriate code
-	if position == 0 {
-		os.Exit(0)
-	} else {
-		os.Exit(position)
+	cfg := changecase.EqConfig{
+		Options:        opts,
+		AllDifferences: *allDifferencesFlag,
+		Fuzzy:          fuzzy,
+		Threshold:      *fuzzyFlag,
+		Algorithm:      algo,
+		Majority:       *majorityFlag,
+		AllLines:       *allLinesFlag,
+		Confusables:    *confusablesFlag,
+		Match:          matchMode,
+		Diff:           *diffFlag,
+		Width:          changecase.DiffWidth(*widthFlag),
+		Files:          *fileModeFlag,
+		JSON:           *jsonFlag,
+		Unordered:      *unorderedFlag,
+		Numeric:        *numericFlag,
+		Tolerance:      changecase.Tolerance{Absolute: *toleranceFlag, Relative: *relToleranceFlag},
+		Batch:          *batchFlag,
+		BatchFormat:    *batchFormatFlag,
+		Parallel:       *parallelFlag,
+		Secret:         *secretFlag,
+		Unit:           unit,
+		Format:         format,
+		Verbose:        *verboseModeFlag,
+		Colors:         colors,
+	}
+
+	// Secret mode reads its own inputs and never prints anything about them
+	if cfg.Secret && !onlyFlags("secret", "q") {
+		fmt.Fprintln(os.Stderr, "Error: -secret cannot be combined with other options")
+		os.Exit(1)
+	}
+
+	// Numbers may be compared within a tolerance by -json and -numeric
+	if cfg.Tolerance.Absolute < 0 || cfg.Tolerance.Relative < 0 {
+		fmt.Fprintln(os.Stderr, "Error: -tolerance and -rel-tolerance must not be negative")
+		os.Exit(1)
+	}
+	if !cfg.JSON && !cfg.Numeric && (cfg.Tolerance != (changecase.Tolerance{}) || cfg.Unordered) {
+		fmt.Fprintln(os.Stderr, "Error: -tolerance and -rel-tolerance require -json or -numeric, and -unordered requires -json")
+		os.Exit(1)
+	}
+
+	// Every mode other than the comparison of strings only takes some options
+	switch {
+	case cfg.Secret:
+	case cfg.Batch != "":
+		if !onlyFlags("batch", "batch-format", "parallel", "i", "Z", "b", "w", "eol", "skeleton", "ignore", "mask", "unit", "q", "v", "color") {
+			fmt.Fprintln(os.Stderr, "Error: -batch only supports the -batch-format, -parallel, -i, -Z, -b, -w, -eol, -skeleton, -ignore, -mask, -unit, -q, -v and -color options")
+			os.Exit(1)
+		}
+		if cfg.Parallel < 1 {
+			fmt.Fprintln(os.Stderr, "Error: -parallel must be at least 1")
+			os.Exit(1)
+		}
+		if flag.NArg() != 0 {
+			fmt.Fprintln(os.Stderr, "Error: -batch reads its pairs from the manifest, not from arguments")
+			os.Exit(1)
+		}
+	case cfg.JSON:
+		if !onlyFlags("json", "tolerance", "rel-tolerance", "unordered", "f", "q", "v", "color") {
+			fmt.Fprintln(os.Stderr, "Error: -json only supports the -tolerance, -rel-tolerance, -unordered, -f, -q, -v and -color options")
+			os.Exit(1)
+		}
+	case cfg.Numeric:
+		if !onlyFlags("numeric", "tolerance", "rel-tolerance", "i", "unit", "q", "v", "color") {
+			fmt.Fprintln(os.Stderr, "Error: -numeric only supports the -tolerance, -rel-tolerance, -i, -unit, -q, -v and -color options")
+			os.Exit(1)
+		}
+	case cfg.Diff != "":
+	case cfg.Files:
+		if cfg.AllDifferences || cfg.Fuzzy || opts.Remapped() || cfg.Confusables || cfg.Match != "" {
+			fmt.Fprintln(os.Stderr, "Error: -f only supports the -i, -q, -v and -color options")
+			os.Exit(1)
+		}
+	}
+
+	output, code, err := changecase.RunEq(cfg, flag.Args(), os.Stdin)
+	if errors.Is(err, changecase.ErrArgumentCount) {
+		fmt.Fprintln(os.Stderr, "Invalid number of arguments")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if !*quietModeFlag {
+		fmt.Print(output)
 	}
+	os.Exit(code)
 }
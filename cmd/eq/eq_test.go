@@ -2,13 +2,58 @@ package main
 
 import (
 	"bytes"
+	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
+// eqBinary is the eq binary built once by TestMain for every test
+var eqBinary string
+
+// TestMain builds the binary before running the tests and removes it after
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "eq_test")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create a build directory: %v\n", err)
+		os.Exit(1)
+	}
+	eqBinary = filepath.Join(dir, "eq_test_binary")
+	if output, err := exec.Command("go", "build", "-o", eqBinary, "eq.go").CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to build test binary: %v\n%s", err, output)
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// runEq runs eq with the given arguments and stdin, and returns its standard
+// output, its error output and its exit code
+func runEq(t *testing.T, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+	cmd := exec.Command(eqBinary, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	exitCode := 0
+	if exiterr, ok := err.(*exec.ExitError); ok {
+		exitCode = exiterr.ExitCode()
+	} else if err != nil {
+		t.Fatalf("Failed to run eq: %v", err)
+	}
+	return stdout.String(), stderr.String(), exitCode
+}
+
 // TestStringComparison tests the basic functionality of string comparison
 func TestStringComparison(t *testing.T) {
 	tests := []struct {
@@ -237,6 +282,9 @@ func TestStdinInput(t *testing.T) {
 	}{
 		{"matching strings", "hello\nhello", 0},
 		{"different strings", "hello\nhallo", 2},
+		{"utf-8 byte order mark", "\xEF\xBB\xBFhello\nhello", 0},
+		{"utf-16le", "\xFF\xFEh\x00i\x00\n\x00h\x00i\x00", 0},
+		{"utf-16be mismatch", "\xFE\xFF\x00h\x00i\x00\n\x00h\x00o", 2},
 		// Removed the empty lines test from here and put it in its own test function
 	}
 
@@ -359,3 +407,918 @@ func TestErrorCases(t *testing.T) {
 		}
 	})
 }
+
+// TestAllDifferences tests the -a flag for listing every difference
+func TestAllDifferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		str1     string
+		str2     string
+		expected []string
+		exitCode int
+	}{
+		{"identical strings", "hello", "hello", []string{"Edit distance: 0", "Similarity: 100.00%"}, 0},
+		{"two changes", "kitten", "sitting", []string{"1c1", "5c5", "6a7", "Edit distance: 5"}, 1},
+		{"insertion", "abc", "abcxyz", []string{"3a4,6", "> \"xyz\"", "Similarity: 66.67%"}, 4},
+		{"deletion", "xyzabc", "abc", []string{"1,3d0", "< \"xyz\""}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, exitCode := runEq(t, "", "-a", tt.str1, tt.str2)
+
+			if exitCode != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, exitCode)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(stdout, want) {
+					t.Errorf("Expected output to contain '%s', got '%s'", want, stdout)
+				}
+			}
+		})
+	}
+}
+
+// TestFuzzyComparison tests the -fuzzy and -algo flags; the scores
+// themselves are tested with the library
+func TestFuzzyComparison(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+		exitCode int
+	}{
+		{"within threshold", []string{"-fuzzy", "0.9", "Jon Smith", "John Smith"}, "0.9000", 0},
+		{"beyond threshold", []string{"-fuzzy", "0.95", "Jon Smith", "John Smith"}, "0.9000", 1},
+		{"case-insensitive", []string{"-i", "-fuzzy", "1", "Hello", "hello"}, "1.0000", 0},
+		{"jaro-winkler", []string{"-fuzzy", "0.96", "-algo", "jaro-winkler", "MARTHA", "MARHTA"}, "0.9611", 0},
+		{"verbose shows score", []string{"-v", "-fuzzy", "0.9", "Jon Smith", "John Smith"}, "Similarity: 0.9000", 0},
+		{"zero threshold", []string{"-fuzzy", "0", "abc", "xyz"}, "0.0000", 0},
+		{"verbose distance", []string{"-v", "-fuzzy", "0.5", "-algo", "damerau", "ab", "ba"}, "Distance: 1", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, exitCode := runEq(t, "", tt.args...)
+
+			if exitCode != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, exitCode)
+			}
+			if !strings.Contains(stdout, tt.expected) {
+				t.Errorf("Expected output to contain '%s', got '%s'", tt.expected, stdout)
+			}
+		})
+	}
+
+	// Test with an unknown algorithm and with -algo but no -fuzzy
+	for _, args := range [][]string{{"-fuzzy", "0.9", "-algo", "soundex", "a", "b"}, {"-algo", "jaro", "a", "a"}} {
+		t.Run("error "+strings.Join(args, " "), func(t *testing.T) {
+			if _, _, exitCode := runEq(t, "", args...); exitCode == 0 {
+				t.Errorf("Expected an error, got none")
+			}
+		})
+	}
+}
+
+// TestFileComparison tests the -f flag for comparing file contents
+func TestFileComparison(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	base := writeFile("base.txt", "one\ntwo\nthr€e x\nfour\n")
+	same := writeFile("same.txt", "one\ntwo\nthr€e x\nfour\n")
+	changed := writeFile("changed.txt", "one\ntwo\nthr€E y\nfive\n")
+	short := writeFile("short.txt", "one\ntwo\n")
+	invalid1 := writeFile("invalid1.txt", "x\xff\xfea\n")
+	invalid2 := writeFile("invalid2.txt", "x\xff\xfdb\n")
+	invalid3 := writeFile("invalid3.txt", "x\xff\xfeb\n")
+	bom := writeFile("bom.txt", "\xEF\xBB\xBFone\ntwo\nthr€e x\nfour\n")
+	utf16 := writeFile("utf16.txt", "\xFF\xFEo\x00n\x00e\x00\n\x00t\x00w\x00o\x00\n\x00")
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+		exitCode int
+	}{
+		{"identical files", []string{"-f", base, same}, []string{"0"}, 0},
+		{"utf-8 mismatch", []string{"-f", base, changed}, []string{"line 3, column 5, byte offset 14"}, 1},
+		{"case-insensitive", []string{"-f", "-i", base, changed}, []string{"line 3, column 7, byte offset 16"}, 1},
+		{"shorter file", []string{"-f", base, short}, []string{"line 3, column 1, byte offset 8"}, 1},
+		{"verbose context", []string{"-f", "-v", base, changed}, []string{"2: two", "3: thr€[e] x", "3: thr€[E] y", "4: five"}, 1},
+		{"verbose end of file", []string{"-f", "-v", base, short}, []string{"3: [END]"}, 1},
+		{"invalid bytes differ", []string{"-f", invalid1, invalid2}, []string{"line 1, column 3, byte offset 2"}, 1},
+		{"after invalid bytes", []string{"-f", "-v", invalid1, invalid3}, []string{"line 1, column 4 (byte offset 3)", "1: x\ufffd\ufffd[b]"}, 1},
+		{"byte order mark", []string{"-f", bom, same}, []string{"0"}, 0},
+		{"utf-16 file", []string{"-f", short, utf16}, []string{"0"}, 0},
+		{"utf-16 diff", []string{"-f", "-diff", "unified", base, utf16}, []string{" two", "-thr€e x"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, exitCode := runEq(t, "", tt.args...)
+
+			if exitCode != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, exitCode)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(stdout, want) {
+					t.Errorf("Expected output to contain '%s', got '%s'", want, stdout)
+				}
+			}
+		})
+	}
+
+	// Test with a missing file
+	t.Run("missing file", func(t *testing.T) {
+		_, stderr, exitCode := runEq(t, "", "-f", base, filepath.Join(dir, "missing.txt"))
+		if exitCode == 0 || !strings.Contains(stderr, "missing.txt") {
+			t.Errorf("Expected an error naming the missing file, got exit code %d and '%s'", exitCode, stderr)
+		}
+	})
+}
+
+// TestMultipleStrings tests comparing more than two strings
+func TestMultipleStrings(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		stdin    string
+		expected []string
+		exitCode int
+	}{
+		{"all equal", []string{"1.4.0", "1.4.0", "1.4.0"}, "", []string{"0"}, 0},
+		{"one outlier", []string{"1.4.0", "1.4.0", "1.4.1"}, "", []string{"string 3 differs at position 5"}, 1},
+		{"first is the outlier", []string{"1.3.9", "1.4.0", "1.4.0"}, "", []string{"string 2 differs at position 3", "string 3 differs at position 3"}, 2},
+		{"majority", []string{"-majority", "1.3.9", "1.4.0", "1.4.0"}, "", []string{"string 1 differs at position 3"}, 1},
+		{"case-insensitive", []string{"-i", "v1.4.0", "V1.4.0", "v1.4.0"}, "", []string{"0"}, 0},
+		{"verbose", []string{"-v", "-majority", "abc", "abd", "abd"}, "", []string{"against string 2 (majority)", "String 2: ab[d]", "String 1: ab[c]"}, 1},
+		{"all stdin lines", []string{"-n"}, "same\nsame\nsame\nsome\n", []string{"string 4 differs at position 2"}, 1},
+		{"outliers capped", []string{"-n", "-q"}, "a\n" + strings.Repeat("b\n", 256), nil, 255},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, exitCode := runEq(t, tt.stdin, tt.args...)
+
+			if exitCode != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, exitCode)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(stdout, want) {
+					t.Errorf("Expected output to contain '%s', got '%s'", want, stdout)
+				}
+			}
+		})
+	}
+}
+
+// TestWhitespaceModes tests the -Z, -b, -w and -eol flags; the normalization
+// itself is tested with the library
+func TestWhitespaceModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+		exitCode int
+	}{
+		{"trailing spaces", []string{"-Z", "hello  ", "hello"}, "0", 0},
+		{"collapse runs", []string{"-b", "a  \t b", "a b"}, "0", 0},
+		{"ignore all", []string{"-w", " a b c ", "abc"}, "0", 0},
+		{"crlf", []string{"-eol", "a\r\nb", "a\nb"}, "0", 0},
+		{"position in original input", []string{"-w", "a b c", "abx"}, "5", 5},
+		{"case-insensitive", []string{"-i", "-b", "Hello  World", "hello world"}, "0", 0},
+		{"verbose shows both positions", []string{"-v", "-w", "a b c", "abx"}, "position 5 (string 1) and 3 (string 2)", 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, exitCode := runEq(t, "", tt.args...)
+
+			if exitCode != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, exitCode)
+			}
+			if output := strings.TrimSpace(stdout); !strings.Contains(output, tt.expected) {
+				t.Errorf("Expected output to contain '%s', got '%s'", tt.expected, output)
+			}
+		})
+	}
+}
+
+// TestPositionUnits tests the -unit flag and the units shown in verbose mode
+func TestPositionUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+		exitCode int
+	}{
+		{"rune", []string{"-unit", "rune", "café!", "café?"}, "5", 5},
+		{"byte", []string{"-unit", "byte", "café!", "café?"}, "6", 5},
+		{"column with wide runes", []string{"-unit", "column", "日本語x", "日本語y"}, "7", 4},
+		{"column with tab", []string{"-unit", "column", "a\tb", "a\tc"}, "9", 3},
+		{"line and column", []string{"-unit", "line:col", "ab\ncd", "ab\nce"}, "2:2", 5},
+		{"match is still zero", []string{"-unit", "byte", "café", "café"}, "0", 0},
+		{"verbose shows all units", []string{"-v", "café!", "café?"}, "rune 5, byte 6 (offset 5), column 5, line:col 1:5", 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, exitCode := runEq(t, "", tt.args...)
+
+			if exitCode != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, exitCode)
+			}
+			if output := strings.TrimSpace(stdout); !strings.Contains(output, tt.expected) {
+				t.Errorf("Expected output to contain '%s', got '%s'", tt.expected, output)
+			}
+		})
+	}
+
+	// Test with an unknown unit, and with modes that report their own positions
+	for _, args := range [][]string{
+		{"-unit", "furlong", "a", "b"},
+		{"-unit", "byte", "-f", "eq.go", "eq.go"},
+		{"-unit", "rune", "-a", "a", "a"},
+		{"-unit", "byte", "-fuzzy", "0.5", "é", "é"},
+		{"-unit", "column", "-match", "prefix", "é", "é"},
+		{"-unit", "byte", "-diff", "unified", "a", "b"},
+	} {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			if _, _, exitCode := runEq(t, "", args...); exitCode == 0 {
+				t.Errorf("Expected an error, got none")
+			}
+		})
+	}
+}
+
+// TestVisibleVerboseOutput tests escapes, carets, code points and colors in verbose mode
+func TestVisibleVerboseOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+		absent   []string
+	}{
+		{"tab vs space", []string{"-v", "a\tb", "a b"},
+			[]string{"String 1: a[\\t]b", "String 2: a[ ]b", "Code points: U+0009 '\\t' vs U+0020 ' '"}, nil},
+		{"nbsp", []string{"-v", "a\u00a0b", "a b"},
+			[]string{"a[\\u00a0]b", "U+00A0"}, nil},
+		{"zero width space", []string{"-v", "a\u200bb", "ab"},
+			[]string{"a[\\u200b]b", "U+200B"}, nil},
+		{"carriage return", []string{"-v", "ab\r", "ab"},
+			[]string{"ab[\\r]", "ab[END]", "vs END"}, nil},
+		{"caret aligned by width", []string{"-v", "日本x", "日本y"},
+			[]string{"String 1: 日本[x]\n" + strings.Repeat(" ", 15) + "^\n"}, nil},
+		{"no color when piped", []string{"-v", "abc", "abd"},
+			nil, []string{"\x1b["}},
+		{"color always", []string{"-v", "-color", "always", "abc", "abd"},
+			[]string{"ab[\x1b[1;31mc\x1b[0m]"}, nil},
+		{"color never", []string{"-v", "-color", "never", "abc", "abd"},
+			nil, []string{"\x1b["}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, _ := runEq(t, "", tt.args...)
+
+			for _, want := range tt.expected {
+				if !strings.Contains(stdout, want) {
+					t.Errorf("Expected output to contain %q, got %q", want, stdout)
+				}
+			}
+			for _, unwanted := range tt.absent {
+				if strings.Contains(stdout, unwanted) {
+					t.Errorf("Expected output not to contain %q, got %q", unwanted, stdout)
+				}
+			}
+		})
+	}
+
+	// Test with an unknown color mode
+	t.Run("unknown color mode", func(t *testing.T) {
+		if _, _, exitCode := runEq(t, "", "-color", "sometimes", "a", "b"); exitCode == 0 {
+			t.Errorf("Expected an error with an unknown color mode, got none")
+		}
+	})
+}
+
+// TestConfusables tests the -confusables and -skeleton flags
+func TestConfusables(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+		exitCode int
+	}{
+		{"explain cyrillic a", []string{"-confusables", "p\u0430ypal", "paypal"},
+			[]string{"2", "U+0430 CYRILLIC SMALL LETTER A is confusable with U+0061 LATIN SMALL LETTER A", "only differ by confusable characters"}, 2},
+		{"not confusable", []string{"-confusables", "abc", "abd"},
+			[]string{"are not confusable", "differ by more than confusable characters"}, 3},
+		{"skeleton match", []string{"-skeleton", "p\u0430ypal", "paypal"}, []string{"0"}, 0},
+		{"skeleton digit zero", []string{"-skeleton", "C0NFIG", "CONFIG"}, []string{"0"}, 0},
+		{"skeleton still differs", []string{"-skeleton", "p\u0430ypal", "paypai"}, []string{"6"}, 6},
+		{"skeleton case-insensitive", []string{"-skeleton", "-i", "ΗELLO", "hello"}, []string{"0"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, exitCode := runEq(t, "", tt.args...)
+
+			if exitCode != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, exitCode)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(stdout, want) {
+					t.Errorf("Expected output to contain '%s', got '%s'", want, stdout)
+				}
+			}
+		})
+	}
+}
+
+// TestSecretMode tests the -secret flag for constant-time comparison
+func TestSecretMode(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		token    string // the value of EQ_TOKEN, if set
+		stdin    string
+		exitCode int
+	}{
+		{"env and file match", []string{"-secret", "env:EQ_TOKEN", "file:" + tokenFile}, "s3cret", "", 0},
+		{"env and file differ", []string{"-secret", "env:EQ_TOKEN", "file:" + tokenFile}, "s3creX", "", 1},
+		{"different lengths", []string{"-secret", "env:EQ_TOKEN", "file:" + tokenFile}, "s3cret!", "", 1},
+		{"two stdin lines", []string{"-secret"}, "", "s3cret\ns3cret\n", 0},
+		{"stdin and file", []string{"-secret", "-", "file:" + tokenFile}, "", "s3cret\r\n", 0},
+		{"quiet is allowed", []string{"-secret", "-q", "-", "-"}, "", "a\nb\n", 1},
+		{"literal values are rejected", []string{"-secret", "s3cret", "s3cret"}, "", "", 1},
+		{"unset variable", []string{"-secret", "env:EQ_UNSET_TOKEN", "env:EQ_UNSET_TOKEN"}, "", "", 1},
+		{"verbose is rejected", []string{"-secret", "-v", "-", "-"}, "", "a\na\n", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.token != "" {
+				t.Setenv("EQ_TOKEN", tt.token)
+			}
+			stdout, stderr, exitCode := runEq(t, tt.stdin, tt.args...)
+
+			if exitCode != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, exitCode)
+			}
+			if stdout != "" {
+				t.Errorf("Expected no output in secret mode, got '%s'", stdout)
+			}
+			if strings.Contains(stderr, "s3cre") {
+				t.Errorf("Expected errors not to reveal the secret, got '%s'", stderr)
+			}
+		})
+	}
+}
+
+// TestMatchModes tests the -match flag
+func TestMatchModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+		exitCode int
+	}{
+		{"prefix", []string{"-match", "prefix", "v1.4.0", "v1."}, "0", 0},
+		{"prefix mismatch", []string{"-match", "prefix", "v1.4.0", "v2."}, "1", 1},
+		{"suffix case-insensitive", []string{"-i", "-match", "suffix", "Report.PDF", ".pdf"}, "0", 0},
+		{"contains", []string{"-match", "contains", "hello world", "o w"}, "0", 0},
+		{"glob", []string{"-match", "glob", "report-2024.csv", "report-*.csv"}, "0", 0},
+		{"glob is anchored", []string{"-match", "glob", "my-report.csv", "report*"}, "1", 1},
+		{"regex", []string{"-match", "regex", "build 1234 ok", `^build \d+`}, "0", 0},
+		{"regex case-insensitive", []string{"-i", "-match", "regex", "BUILD ok", "build"}, "0", 0},
+		{"verbose span", []string{"-v", "-match", "regex", "build 1234 ok", "[0-9]+"}, "at positions 7-10", 0},
+		{"verbose highlights span", []string{"-v", "-match", "contains", "日本語テキスト", "語テ"}, "Match: 日本[語テ]キスト", 0},
+		{"verbose no match", []string{"-v", "-match", "suffix", "hello", "xyz"}, "does not match", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, exitCode := runEq(t, "", tt.args...)
+
+			if exitCode != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, exitCode)
+			}
+			if output := strings.TrimSpace(stdout); !strings.Contains(output, tt.expected) {
+				t.Errorf("Expected output to contain '%s', got '%s'", tt.expected, output)
+			}
+		})
+	}
+
+	// Test with an invalid regexp and an unknown mode
+	for _, args := range [][]string{{"-match", "regex", "a", "("}, {"-match", "fnmatch", "a", "a"}} {
+		t.Run("error "+strings.Join(args, " "), func(t *testing.T) {
+			if _, _, exitCode := runEq(t, "", args...); exitCode == 0 {
+				t.Errorf("Expected an error, got none")
+			}
+		})
+	}
+}
+
+// TestDiffOutput tests the -diff unified and side-by-side formats
+func TestDiffOutput(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("COLUMNS", "")
+
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "a.txt")
+	file2 := filepath.Join(dir, "b.txt")
+	if err := os.WriteFile(file1, []byte("one\ntwo\nthree\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file2, []byte("one\n2\nthree"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+		exitCode int
+	}{
+		{"unified identical", []string{"-diff", "unified", "a\nb", "a\nb"}, "", 0},
+		{"unified change", []string{"-diff", "unified", "a\nb\nc", "a\nB\nc"},
+			"--- string 1\n+++ string 2\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c", 1},
+		{"unified append", []string{"-diff", "unified", "a", "a\nb"}, "@@ -1 +1,2 @@\n a\n+b", 1},
+		{"unified separate hunks", []string{"-diff", "unified", "1\n2\n3\n4\n5\n6\n7\n8\n9\n10", "x\n2\n3\n4\n5\n6\n7\n8\n9\ny"},
+			"@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+y", 1},
+		{"unified ignores case", []string{"-i", "-diff", "unified", "A\nb", "a\nb"}, "", 0},
+		{"unified ignores whitespace", []string{"-w", "-diff", "unified", "a b\nc", "ab\nc "}, "", 0},
+		{"unified eol", []string{"-eol", "-diff", "unified", "a\r\nb", "a\nb"}, "", 0},
+		{"unified files", []string{"-f", "-diff", "unified", file1, file2},
+			"@@ -1,3 +1,3 @@\n one\n-two\n-three\n+2\n+three\n\\ No newline at end of file", 1},
+		{"unified stdin", []string{"-f", "-diff", "unified", file1, "-"},
+			"--- " + file1 + "\n+++ -\n@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n", 1},
+		{"side-by-side", []string{"-diff", "side-by-side", "-width", "23", "a\nhello\nc", "a\nhallo\nc\nd"},
+			"a            a\nh[e]llo    | h[a]llo\nc            c\n           > [d]", 1},
+		{"side-by-side removed", []string{"-diff", "side-by-side", "-width", "23", "a\nb", "a"}, "[b]        <", 1},
+		{"side-by-side truncates", []string{"-diff", "side-by-side", "-width", "23", "abcdefghijklmnop", "abcdefghijklmnop"},
+			"abcdefghi…   abcdefghi…", 0},
+		{"verbose counts", []string{"-v", "-diff", "unified", "a\nb", "a\nc\nd"}, "1 line(s) removed, 2 line(s) added", 1},
+		{"quiet", []string{"-q", "-diff", "unified", "a", "b"}, "", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, exitCode := runEq(t, "one\n2\nthree\n", tt.args...)
+
+			if exitCode != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, exitCode)
+			}
+			if tt.expected == "" && tt.exitCode == 0 && stdout != "" {
+				t.Errorf("Expected no output, got '%s'", stdout)
+			}
+			if !strings.Contains(stdout, tt.expected) {
+				t.Errorf("Expected output to contain '%s', got '%s'", tt.expected, stdout)
+			}
+		})
+	}
+
+	// Test with an unknown format and an unsupported combination
+	for _, args := range [][]string{{"-diff", "context", "a", "b"}, {"-a", "-diff", "unified", "a", "b"}, {"-diff", "unified"}, {"-f", "-diff", "unified", "-", "-"}} {
+		t.Run("error "+strings.Join(args, " "), func(t *testing.T) {
+			if _, _, exitCode := runEq(t, "", args...); exitCode == 0 {
+				t.Errorf("Expected an error, got none")
+			}
+		})
+	}
+}
+
+// TestIgnoreMasks tests the -ignore and -mask flags; what each mask matches
+// is tested with the library
+func TestIgnoreMasks(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	log1 := "2024-03-01T10:00:00Z req 123e4567-e89b-12d3-a456-426614174000 from 10.0.0.1 at 0x7ffd5c1e ok"
+	log2 := "2025-11-30T23:59:59.123+01:00 req 9b2e4567-e89b-12d3-a456-4266141749ff from 192.168.1.20 at 0xdeadbeef ok"
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+		exitCode int
+	}{
+		{"unmasked", []string{log1, log2}, "4", 4},
+		{"all masks", []string{"-mask", "uuid,iso8601,ipv4,hexaddr", log1, log2}, "0", 0},
+		{"repeated masks", []string{"-mask", "uuid", "-mask", "iso8601", "-mask", "ipv4", "-mask", "hexaddr", log1, log2}, "0", 0},
+		{"ignore regexp", []string{"-ignore", `pid=[0-9]+`, "pid=12 ok", "pid=345 ok"}, "0", 0},
+		{"repeated ignore", []string{"-ignore", `pid=[0-9]+`, "-ignore", `took [0-9]+ms`, "pid=1 took 5ms", "pid=22 took 120ms"}, "0", 0},
+		{"with whitespace", []string{"-w", "-mask", "ipv4", "from  10.0.0.1 ", "from 10.0.0.2"}, "0", 0},
+		{"verbose lists regions", []string{"-v", "-mask", "ipv4", "from 10.0.0.1", "from 10.0.0.2"},
+			"Masked in string 1: ipv4 at positions 6-13: 10.0.0.1\nMasked in string 2: ipv4 at positions 6-13: 10.0.0.2", 0},
+		{"verbose custom", []string{"-v", "-ignore", `#[0-9]+`, "job #12 ok", "job #7 ko"}, "Masked in string 2: #[0-9]+ at positions 5-6: #7", 9},
+		{"multiple strings", []string{"-mask", "hexaddr", "at 0x1", "at 0x2", "at 0x3"}, "0", 0},
+		{"diff lines", []string{"-diff", "unified", "-mask", "iso8601", "2024-01-01 start\nstop", "2024-02-02 start\nstop"}, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, exitCode := runEq(t, "", tt.args...)
+
+			if exitCode != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, exitCode)
+			}
+			if output := strings.TrimSpace(stdout); !strings.Contains(output, tt.expected) {
+				t.Errorf("Expected output to contain '%s', got '%s'", tt.expected, output)
+			}
+		})
+	}
+
+	// Test with an unknown mask and an invalid regexp
+	for _, args := range [][]string{{"-mask", "email", "a", "a"}, {"-ignore", "(", "a", "a"}} {
+		t.Run("error "+strings.Join(args, " "), func(t *testing.T) {
+			if _, _, exitCode := runEq(t, "", args...); exitCode == 0 {
+				t.Errorf("Expected an error, got none")
+			}
+		})
+	}
+}
+
+// TestJSONMode tests the -json flag; the structural comparison itself is
+// tested with the library
+func TestJSONMode(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "expected.json")
+	file2 := filepath.Join(dir, "actual.json")
+	if err := os.WriteFile(file1, []byte("{\n  \"items\": [1, 2, 3],\n  \"name\": \"x\"\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file2, []byte(`{"name":"x","items":[3,2,1]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		input    string
+		expected string
+		exitCode int
+	}{
+		{"equal", []string{"-json", `{"a":1,"b":[true,null]}`, `{ "b": [true, null], "a": 1.0 }`}, "", "0", 0},
+		{"nested path", []string{"-json", `{"items":[{},{},{},{"name":"a"}]}`, `{"items":[{},{},{},{"name":"b"}]}`}, "", ".items[3].name", 1},
+		{"tolerance", []string{"-json", "-tolerance", "1e-6", `{"x":1.0000001}`, `{"x":1}`}, "", "0", 0},
+		{"relative tolerance", []string{"-json", "-rel-tolerance", "1e-5", `{"x":1000001}`, `{"x":1000000}`}, "", "0", 0},
+		{"files ordered", []string{"-json", "-f", file1, file2}, "", ".items[0]", 1},
+		{"files unordered", []string{"-json", "-f", "-unordered", file1, file2}, "", "0", 0},
+		{"stdin documents", []string{"-json"}, "{\"a\":\n  [1, 2]}\n{\"a\": [1, 2]}\n", "0", 0},
+		{"verbose", []string{"-v", "-json", `{"a":1}`, `{"a":"1"}`}, "", "JSON documents differ at .a: types differ (number vs string)\nDocument 1: 1\nDocument 2: \"1\"", 1},
+		{"verbose missing", []string{"-v", "-json", `{"a":1}`, `{}`}, "", "only in first document\nDocument 1: 1\nDocument 2: (missing)", 1},
+		{"quiet", []string{"-q", "-json", `[1]`, `[2]`}, "", "", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, exitCode := runEq(t, tt.input, tt.args...)
+
+			if exitCode != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, exitCode)
+			}
+			if output := strings.TrimSpace(stdout); !strings.Contains(output, tt.expected) {
+				t.Errorf("Expected output to contain '%s', got '%s'", tt.expected, output)
+			}
+		})
+	}
+
+	// Test with invalid JSON and unsupported options
+	failures := []struct {
+		args  []string
+		input string
+	}{
+		{[]string{"-json", `{"a":`, `{}`}, ""},
+		{[]string{"-json", "-i", "{}", "{}"}, ""},
+		{[]string{"-unordered", "a", "a"}, ""},
+		{[]string{"-json"}, "{} {"},
+	}
+	for _, tt := range failures {
+		t.Run("error "+strings.Join(tt.args, " "), func(t *testing.T) {
+			if _, _, exitCode := runEq(t, tt.input, tt.args...); exitCode == 0 {
+				t.Errorf("Expected an error, got none")
+			}
+		})
+	}
+}
+
+// TestNumericMode tests the -numeric flag and its tolerances; the token
+// comparison itself is tested with the library
+func TestNumericMode(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+		exitCode int
+	}{
+		{"equal values", []string{"-numeric", "x=1.0 y=2", "x=1 y=2.00"}, "0", 0},
+		{"differs", []string{"-numeric", "t=1.0000001s", "t=1.0s"}, "3", 3},
+		{"version", []string{"-numeric", "v1.2.3", "v1.2.30"}, "3", 3},
+		{"ipv4 address", []string{"-numeric", "host 10.0.0.1 up", "host 10.0.0.10 up"}, "6", 6},
+		{"beyond float64", []string{"-numeric", "9007199254740993", "9007199254740992"}, "1", 1},
+		{"absolute", []string{"-numeric", "-tolerance", "1e-6", "t=1.0000001s", "t=1.0s"}, "0", 0},
+		{"relative", []string{"-numeric", "-rel-tolerance", "0.01", "total 1005 ms", "total 1000 ms"}, "0", 0},
+		{"case-insensitive", []string{"-numeric", "-i", "1.0 Apples", "1 apples"}, "0", 0},
+		{"unit", []string{"-numeric", "-unit", "byte", "é 1.5", "é 1.6"}, "4", 3},
+		{"verbose", []string{"-v", "-numeric", "t=1.5s", "t=1.25s"},
+			"Strings differ at token 3 (position 3)\nToken 1: \"1.5\" (number)\nToken 2: \"1.25\" (number)\nNumeric difference: 0.25", 3},
+		{"verbose missing", []string{"-v", "-numeric", "a 1", "a 1 2"}, "Token 1: (missing)", 4},
+		{"position capped", []string{"-numeric", strings.Repeat("x", 299) + " 1", strings.Repeat("x", 299) + " 2"}, "301", 255},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, exitCode := runEq(t, "", tt.args...)
+
+			if exitCode != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, exitCode)
+			}
+			if output := strings.TrimSpace(stdout); !strings.Contains(output, tt.expected) {
+				t.Errorf("Expected output to contain '%s', got '%s'", tt.expected, output)
+			}
+		})
+	}
+
+	// Test with a negative tolerance, a tolerance without a mode and an unsupported option
+	for _, args := range [][]string{{"-numeric", "-tolerance", "-1", "a", "a"}, {"-tolerance", "1", "a", "a"}, {"-numeric", "-w", "a", "a"}} {
+		t.Run("error "+strings.Join(args, " "), func(t *testing.T) {
+			if _, _, exitCode := runEq(t, "", args...); exitCode == 0 {
+				t.Errorf("Expected an error, got none")
+			}
+		})
+	}
+}
+
+// TestBatchMode tests the -batch flag; parsing each manifest format is
+// tested with the library
+func TestBatchMode(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	dir := t.TempDir()
+	tsv := filepath.Join(dir, "golden.tsv")
+	if err := os.WriteFile(tsv, []byte("hello\thello\tgreeting\na\\tb\ta\\tb\n\nabc\tabx\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ndjson := filepath.Join(dir, "golden.ndjson")
+	if err := os.WriteFile(ndjson, []byte(`{"name":"one","expected":"x","actual":"x"}`+"\n"+`{"name":"two","expected":"Yes","actual":"yes"}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var many strings.Builder
+	for i := 0; i < 50; i++ {
+		fmt.Fprintf(&many, "value %d\tvalue %d\n", i, i)
+	}
+	manyFile := filepath.Join(dir, "many.tsv")
+	if err := os.WriteFile(manyFile, []byte(many.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		input    string
+		expected string
+		exitCode int
+	}{
+		{"tsv", []string{"-batch", tsv},
+			"", "#  RESULT  POSITION  NAME\n1  ok      -         greeting\n2  ok      -         line 2\n3  FAIL    3         line 4\nSummary: 3 pair(s), 2 passed, 1 failed", 1},
+		{"ndjson", []string{"-batch", ndjson}, "", "2  FAIL    1         two", 1},
+		{"ndjson case-insensitive", []string{"-i", "-batch", ndjson}, "", "Summary: 2 pair(s), 2 passed, 0 failed", 0},
+		{"stdin tsv", []string{"-batch", "-"}, "a\ta\n", "1  ok      -         line 1", 0},
+		{"stdin pairs", []string{"-batch", "-"}, "a\na\nb\nc\n", "2  FAIL    1         lines 3-4", 1},
+		{"forced pairs", []string{"-batch", "-", "-batch-format", "pairs"}, "a\tb\na\tb\n", "Summary: 1 pair(s), 1 passed, 0 failed", 0},
+		{"parallel", []string{"-batch", manyFile, "-parallel", "8"}, "", "50  ok      -         line 50\nSummary: 50 pair(s), 50 passed, 0 failed", 0},
+		{"masks", []string{"-batch", "-", "-mask", "ipv4"}, "from 10.0.0.1\nfrom 10.0.0.2\n", "0 failed", 0},
+		{"verbose", []string{"-v", "-batch", tsv}, "", "Pair 3 (line 4):\nStrings differ at position 3", 1},
+		{"quiet", []string{"-q", "-batch", tsv}, "", "", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, exitCode := runEq(t, tt.input, tt.args...)
+
+			if exitCode != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, exitCode)
+			}
+			if output := strings.TrimSpace(stdout); !strings.Contains(output, tt.expected) {
+				t.Errorf("Expected output to contain '%s', got '%s'", tt.expected, output)
+			}
+		})
+	}
+
+	// Test with malformed manifests and unsupported options
+	failures := []struct {
+		args  []string
+		input string
+	}{
+		{[]string{"-batch", "-"}, "a\nb\nc\n"},
+		{[]string{"-batch", "-", "-batch-format", "tsv"}, "a\n"},
+		{[]string{"-batch", "-"}, "{\"expected\":\"a\"}\n"},
+		{[]string{"-batch", filepath.Join(dir, "missing.tsv")}, ""},
+		{[]string{"-batch", "-", "-a"}, "a\na\n"},
+		{[]string{"-batch", "-", "-parallel", "0"}, "a\na\n"},
+	}
+	for _, tt := range failures {
+		t.Run("error "+strings.Join(tt.args, " "), func(t *testing.T) {
+			if _, _, exitCode := runEq(t, tt.input, tt.args...); exitCode == 0 {
+				t.Errorf("Expected an error, got none")
+			}
+		})
+	}
+}
+
+// TestLongInput tests lines longer than bufio.Scanner's 64KB limit, on stdin
+// and in files
+func TestLongInput(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	long := strings.Repeat("x", 200000)
+	changed := long[:150000] + "y" + long[150001:]
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "long.txt")
+	if err := os.WriteFile(file, []byte("first\n"+long+"\nlast\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		input    string
+		expected string
+	}{
+		{"stdin equal", nil, long + "\n" + long + "\n", "0"},
+		{"stdin differ", nil, long + "\n" + changed + "\n", "150001"},
+		{"stdin crlf", nil, long + "\r\n" + long + "\r\n", "0"},
+		{"stdin shorter", nil, long + "\n" + long[:1000] + "\n", "1001"},
+		{"stdin case-insensitive", []string{"-i"}, strings.ToUpper(long) + "\n" + long, "0"},
+		{"stdin verbose", []string{"-v"}, long + "\n" + changed + "\n", "Strings differ at position 150001"},
+		{"stdin all lines", []string{"-n"}, long + "\n" + long + "\n" + changed + "\n", "string 3 differs at position 150001"},
+		{"file against stdin", []string{"-f", file, "-"}, "first\n" + long + "\nlast\n", "0"},
+		{"file context is clipped", []string{"-v", "-f", file, "-"}, "first\n" + changed + "\nlast\n",
+			"  2: …" + strings.Repeat("x", 40) + "[x]" + strings.Repeat("x", 39) + "…\n  3: last"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, _ := runEq(t, tt.input, tt.args...)
+
+			if stderr != "" {
+				t.Errorf("Unexpected error output '%s'", stderr)
+			}
+			if output := strings.TrimSpace(stdout); !strings.Contains(output, tt.expected) {
+				if len(output) > 300 {
+					output = output[:300] + "..."
+				}
+				t.Errorf("Expected output to contain '%s', got '%s'", tt.expected, output)
+			}
+		})
+	}
+
+	// Test that stdin cannot be used for both files and that a missing second line is an error
+	for _, args := range [][]string{{"-f", "-", "-"}, {}} {
+		t.Run("error "+strings.Join(args, " "), func(t *testing.T) {
+			if _, _, exitCode := runEq(t, long+"\n", args...); exitCode == 0 {
+				t.Errorf("Expected an error, got none")
+			}
+		})
+	}
+}
+
+// TestExitCodeCap tests that a mismatch at rune 256 or later exits with 255
+// instead of wrapping around to 0
+func TestExitCodeCap(t *testing.T) {
+	prefix := strings.Repeat("x", 255)
+	tests := []struct {
+		name     string
+		args     []string
+		input    string
+		expected string
+	}{
+		{"arguments", []string{prefix + "a", prefix + "b"}, "", "256"},
+		{"quiet", []string{"-q", prefix + "a", prefix + "b"}, "", ""},
+		{"stdin", nil, prefix + "a\n" + prefix + "b\n", "256"},
+		{"stdin quiet", []string{"-q"}, prefix + "a\n" + prefix + "b\n", ""},
+		{"far position", nil, prefix + prefix + "a\n" + prefix + prefix + "b\n", "511"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, exitCode := runEq(t, tt.input, tt.args...)
+
+			if exitCode != 255 {
+				t.Errorf("Expected exit code 255, got %d", exitCode)
+			}
+			if output := strings.TrimSpace(stdout); output != tt.expected {
+				t.Errorf("Expected output '%s', got '%s'", tt.expected, output)
+			}
+		})
+	}
+}
+
+// TestCaseFoldingModes tests that -i folds case the same way for arguments,
+// streamed STDIN lines and files
+func TestCaseFoldingModes(t *testing.T) {
+	dir := t.TempDir()
+	pairs := [][2]string{
+		{"İstanbul", "istanbul"},
+		{"ſ", "s"},
+		{"ΣΑΣ", "σας"},
+		{"ẞx", "ßy"},
+		{"a\xffB", "A\xfeb"},
+	}
+
+	for n, pair := range pairs {
+		t.Run(pair[0], func(t *testing.T) {
+			argsOutput, _, argsCode := runEq(t, "", "-i", pair[0], pair[1])
+			stdinOutput, _, stdinCode := runEq(t, pair[0]+"\n"+pair[1]+"\n", "-i")
+			if stdinOutput != argsOutput || stdinCode != argsCode {
+				t.Errorf("Expected STDIN to give %q (exit %d) like arguments, got %q (exit %d)", argsOutput, argsCode, stdinOutput, stdinCode)
+			}
+
+			// files tell invalid bytes apart instead of reading them as U+FFFD
+			if !utf8.ValidString(pair[0] + pair[1]) {
+				return
+			}
+			file1 := filepath.Join(dir, fmt.Sprintf("%d-1.txt", n))
+			file2 := filepath.Join(dir, fmt.Sprintf("%d-2.txt", n))
+			if err := os.WriteFile(file1, []byte(pair[0]), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(file2, []byte(pair[1]), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, _, fileCode := runEq(t, "", "-i", "-f", file1, file2); (fileCode == 0) != (argsCode == 0) {
+				t.Errorf("Expected files to match only when arguments do (exit %d), got exit %d", argsCode, fileCode)
+			}
+		})
+	}
+}
+
+// TestFormatOutput tests the -format flag
+func TestFormatOutput(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	tests := []struct {
+		name     string
+		args     []string
+		stdin    string
+		expected string
+		exitCode int
+	}{
+		{"json match", []string{"-format", "json", "abc", "abc"}, "",
+			`{"match":true,"kind":"none","position1":{"rune":0,"byte":0,"offset":0,"column":0,"line":0,"line_column":0},`, 0},
+		{"json mismatch", []string{"-format", "json", "héllo", "hélp"}, "",
+			`"position1":{"rune":4,"byte":5,"offset":4,"column":4,"line":1,"line_column":4}`, 4},
+		{"json context", []string{"-format", "json", "héllo", "hélp"}, "",
+			`"context1":{"before":"hél","at":"l","after":"o"},"context2":{"before":"hél","at":"p","after":""}}`, 4},
+		{"json ended", []string{"-format", "json", "ab", "abc"}, "", `"kind":"first-ended"`, 3},
+		{"json remapped", []string{"-format", "json", "-w", "a b x", "ab y"}, "",
+			`"position1":{"rune":5,"byte":5,"offset":4,"column":5,"line":1,"line_column":5},"position2":{"rune":4,`, 5},
+		{"json stdin", []string{"-format", "json"}, "a<b\na>b\n", `"context1":{"before":"a","at":"<","after":"b"}`, 2},
+		{"tsv", []string{"-format", "tsv", "a\tb", "a\tc"}, "",
+			"match\tkind\tposition1.rune\tposition1.byte\tposition1.offset\tposition1.column\tposition1.line\tposition1.line_column\tposition2.rune",
+			3},
+		{"tsv escaped", []string{"-format", "tsv", "a\tb", "a\tc"}, "", "\ta\\t\tb\t\ta\\t\tc\t", 3},
+		{"quiet", []string{"-format", "json", "-q", "a", "b"}, "", "", 1},
+		{"text", []string{"-format", "text", "a", "b"}, "", "1", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, exitCode := runEq(t, tt.stdin, tt.args...)
+
+			if exitCode != tt.exitCode {
+				t.Errorf("Expected exit code %d, got %d", tt.exitCode, exitCode)
+			}
+			if !strings.Contains(stdout, tt.expected) {
+				t.Errorf("Expected output to contain '%s', got '%s'", tt.expected, stdout)
+			}
+		})
+	}
+
+	// Test with an unknown format and modes without structured output
+	for _, args := range [][]string{{"-format", "xml", "a", "b"}, {"-format", "json", "-v", "a", "b"},
+		{"-format", "json", "a", "b", "c"}, {"-format", "tsv", "-fuzzy", "0.5", "a", "b"}, {"-format", "json", "-secret", "a", "b"}} {
+		t.Run("error "+strings.Join(args, " "), func(t *testing.T) {
+			if _, _, exitCode := runEq(t, "", args...); exitCode == 0 {
+				t.Errorf("Expected an error, got none")
+			}
+		})
+	}
+}
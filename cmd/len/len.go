@@ -5,7 +5,12 @@ len.go
 -John Taylor
 March 2019
 
-Return the combined string length of all of given command line arguments.
+Return the combined string length of all of given command line arguments,
+or of standard input when there are none. A byte order mark at the start of
+standard input is not counted, and UTF-16 input with a byte order mark is
+measured as UTF-8; arguments are measured as given.
+With -format json or tsv, the length is reported in bytes, runes and display
+columns.
 
 To compile:
 go build -ldflags="-s -w" len.go
@@ -21,16 +26,53 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"unicode/utf8"
+
+	"github.com/jftuga/changecase"
 )
 
 const version = "1.0.0"
 
 func main() {
-	if 1 == len(os.Args) {
-		fmt.Printf("\nUsage: %s \"string\"\n\n", os.Args[0])
-		fmt.Printf("This program assumes that there is only one space between each command line argument.\n")
-		fmt.Printf("The most accurate way to get a string length is to surround all of your command line arguments between double-quotes.\n\n")
+	opts, args, err := changecase.ParseCommandArgs(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if opts.BOM {
+		fmt.Fprintln(os.Stderr, "Error: -bom only applies to commands that output text")
+		os.Exit(1)
+	}
+
+	input := strings.Join(args, " ")
+	if len(args) == 0 {
+		if !changecase.StdinIsPiped() {
+			fmt.Printf("\nUsage: %s [-format json|text|tsv] \"string\"\n\n", os.Args[0])
+			fmt.Printf("This program assumes that there is only one space between each command line argument.\n")
+			fmt.Printf("The most accurate way to get a string length is to surround all of your command line arguments between double-quotes.\n")
+			fmt.Printf("Without arguments, the length of standard input is returned, including any trailing newline.\n\n")
+			os.Exit(1)
+		}
+		data, err := changecase.ReadAllDecoded(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+			os.Exit(1)
+		}
+		input = string(data)
+	}
+
+	if opts.Format == changecase.FormatText {
+		fmt.Println(len(input))
+		return
+	}
+	fields := []changecase.Field{
+		{Name: "input", Value: input},
+		{Name: "bytes", Value: len(input)},
+		{Name: "runes", Value: utf8.RuneCountInString(input)},
+		{Name: "columns", Value: changecase.StringWidth(input)},
+	}
+	if err := changecase.WriteRecord(os.Stdout, opts.Format, fields); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-	fmt.Println(len(strings.Join(os.Args[1:], " ")))
 }
@@ -0,0 +1,231 @@
+// trim reads from standard input, or from each file given, and removes leading and trailing whitespace.
+// Whitespace is Unicode aware: besides spaces, tabs and newlines it includes NBSP, the ideographic space and the
+// zero width no-break space (byte order mark). Options trim only one end, trim every line instead of the input as
+// a whole, or remove a custom set of characters instead of whitespace. UTF-16 input that starts with a byte order
+// mark is decoded first, unless -raw is given.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jftuga/changecase"
+)
+
+const pgmName string = "trim"
+
+func main() {
+	versionFlag := flag.Bool("version", false, "Display version information")
+	helpFlag := flag.Bool("help", false, "Display help information")
+	leftFlag := flag.Bool("l", false, "Only trim the start (like ltrim)")
+	rightFlag := flag.Bool("r", false, "Only trim the end (like rtrim)")
+	linesFlag := flag.Bool("lines", false, "Trim every line instead of the input as a whole")
+	cutsetFlag := flag.String("cutset", "", "Remove these characters instead of whitespace; escapes such as \\t are allowed")
+	bomFlag := flag.Bool("bom", false, "Start the output with a UTF-8 byte order mark")
+	rawFlag := flag.Bool("raw", false, "Read input as raw bytes, without decoding UTF-16 or removing a byte order mark")
+	formatFlag := flag.String("format", changecase.FormatText, "Output format: text, json, tsv")
+	flag.Parse()
+
+	if *versionFlag || *helpFlag {
+		fmt.Printf("%s, v%s\n", pgmName, changecase.PgmVersion)
+		fmt.Println(changecase.PgmUrl)
+		fmt.Println()
+		fmt.Println("Usage:")
+		fmt.Println("  trim < input.txt")
+		fmt.Println("  echo '  text  ' | trim -r")
+		fmt.Println("  trim -lines -cutset '\"' < quoted.txt")
+		fmt.Println("  trim -lines a.txt b.txt > trimmed.txt")
+		fmt.Println()
+		fmt.Println("Removes leading and trailing Unicode whitespace from standard input, or from each file given.")
+		fmt.Println()
+		fmt.Println("Options:")
+		fmt.Println("  -l: Only trim the start (like ltrim)")
+		fmt.Println("  -r: Only trim the end (like rtrim)")
+		fmt.Println("  -lines: Trim every line instead of the input as a whole; line endings (LF or CRLF) are kept")
+		fmt.Println("  -cutset chars: Remove these characters instead of whitespace; escapes such as \\t and \\u00a0 are allowed")
+		fmt.Println("  -bom: Start the output with a UTF-8 byte order mark")
+		fmt.Println("  -raw: Read input as raw bytes, without decoding UTF-16 or removing a UTF-8 byte order mark, for")
+		fmt.Println("        binary input that happens to start with the bytes of a byte order mark")
+		fmt.Println("  -format: Output format: text (default), json, tsv")
+		fmt.Println("Whitespace is every Unicode White_Space character, such as NBSP (U+00A0) and the ideographic space")
+		fmt.Println("(U+3000), plus the zero width space (U+200B), word joiner (U+2060) and zero width no-break space")
+		fmt.Println("or byte order mark (U+FEFF). Files are trimmed independently and written to standard output one")
+		fmt.Println("after the other; \"-\" reads standard input. With -format json or tsv, reports the input and the")
+		fmt.Println("output, with a record per file that starts with its name. Input is decoded from UTF-16 when it")
+		fmt.Println("starts with a byte order mark, unless -raw is given.")
+		return
+	}
+
+	opts, err := newTrimOptions(*leftFlag, *rightFlag, *linesFlag, *cutsetFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	format, err := changecase.ParseFormat(*formatFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *bomFlag && format != changecase.FormatText {
+		fmt.Fprintln(os.Stderr, "Error: -bom only applies to text output")
+		os.Exit(1)
+	}
+
+	if *rawFlag {
+		decodeInput = changecase.RawReader
+	}
+
+	files := flag.Args()
+	if format != changecase.FormatText {
+		writeRecords(format, opts, files)
+		return
+	}
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	// a file that cannot be trimmed is reported, and the rest still are
+	writer := bufio.NewWriter(os.Stdout)
+	if *bomFlag {
+		writer.WriteString(changecase.BOM)
+	}
+	failed := false
+	for _, name := range files {
+		if err := trimFile(name, writer, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", name, err)
+			failed = true
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		os.Exit(1)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// newTrimOptions builds the options selected on the command line
+func newTrimOptions(left, right, lines bool, cutset string) (changecase.TrimOptions, error) {
+	opts := changecase.TrimOptions{PerLine: lines}
+	switch {
+	case left && right:
+		return opts, errors.New("-l and -r cannot be combined; without either, both ends are trimmed")
+	case left:
+		opts.Side = changecase.TrimLeft
+	case right:
+		opts.Side = changecase.TrimRight
+	}
+	var err error
+	opts.Cutset, err = changecase.Unescape(cutset)
+	return opts, err
+}
+
+// decodeInput detects the encoding of input and decodes it to UTF-8; -raw
+// replaces it with changecase.RawReader, which leaves input as it is
+var decodeInput = changecase.DecodeReader
+
+// openInput opens a file argument, where "-" is standard input
+func openInput(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(name)
+}
+
+// trimFile writes the trimmed contents of a file to w. Lines are trimmed as
+// they are read, so -lines works on input of any size; otherwise the whole
+// input is needed to know where its trailing whitespace starts.
+func trimFile(name string, w io.Writer, opts changecase.TrimOptions) error {
+	f, err := openInput(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, _, err := decodeInput(f)
+	if err != nil {
+		return err
+	}
+
+	if !opts.PerLine {
+		input, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, changecase.Trim(string(input), opts))
+		return err
+	}
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			if _, err := io.WriteString(w, changecase.Trim(line, opts)); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// writeRecords reports standard input, or each file, before and after
+// trimming as structured records; the records of files start with the name
+func writeRecords(format string, opts changecase.TrimOptions, files []string) {
+	names := files
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+	var records [][]changecase.Field
+	failed := false
+	for _, name := range names {
+		input, err := readInput(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", name, err)
+			failed = true
+			continue
+		}
+		fields := []changecase.Field{
+			{Name: "input", Value: string(input)},
+			{Name: "output", Value: changecase.Trim(string(input), opts)},
+		}
+		if len(files) > 0 {
+			fields = append([]changecase.Field{{Name: "file", Value: name}}, fields...)
+		}
+		records = append(records, fields)
+	}
+	if len(records) > 0 {
+		if err := changecase.WriteRecords(os.Stdout, format, records); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// readInput reads all of a file argument, where "-" is standard input,
+// decoded to UTF-8
+func readInput(name string) ([]byte, error) {
+	f, err := openInput(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, _, err := decodeInput(f)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+)
+
+func TestTrim(t *testing.T) {
+	tests := []struct {
+		args     []string
+		input    string
+		expected string
+	}{
+		{nil, "  Hello, World!\n\n", "Hello, World!"},
+		{nil, "\u00a0\u3000Hello\ufeff\n", "Hello"},
+		{nil, "  Line 1  \n  Line 2  \n", "Line 1  \n  Line 2"},
+		{nil, "", ""},
+		{[]string{"-l"}, "  Hello  \n", "Hello  \n"},
+		{[]string{"-r"}, "  Hello  \n", "  Hello"},
+		{[]string{"-lines"}, "  Line 1  \n\t Line 2 \t\n", "Line 1\nLine 2\n"},
+		{[]string{"-lines"}, " Line 1 \r\n Line 2 ", "Line 1\r\nLine 2"},
+		{[]string{"-lines", "-r"}, "  Line 1  \n  Line 2  \n", "  Line 1\n  Line 2\n"},
+		{[]string{"-cutset", "\"'"}, "\"'quoted'\"", "quoted"},
+		{[]string{"-cutset", "\\t"}, "\t a \t", " a "},
+		{[]string{"-format", "json"}, " a\tb \n", `{"input":" a\tb \n","output":"a\tb"}` + "\n"},
+		{[]string{"-lines"}, "\xFF\xFE \x00a\x00\n\x00 \x00b\x00", "a\nb"},
+		{[]string{"-lines"}, "\xFE\xFF\x00 \x00a\x00\n", "a\n"},
+		{[]string{"-raw"}, "\xFF\xFE a \n", "\xFF\xFE a"},
+		{[]string{"-bom"}, " a \n", "\ufeffa"},
+		{[]string{"-bom", "-lines"}, "\ufeff a \n", "\ufeffa\n"},
+	}
+
+	for _, test := range tests {
+		output, err := runTrim(test.input, test.args...)
+		if err != nil {
+			t.Fatalf("Error running trim %v: %v", test.args, err)
+		}
+		if output != test.expected {
+			t.Errorf("Args: %v\nInput: %q\nExpected: %q\nGot: %q", test.args, test.input, test.expected, output)
+		}
+	}
+
+	// Test with options that cannot be combined and an invalid escape
+	for _, args := range [][]string{{"-l", "-r"}, {"-cutset", "\\q"}, {"-bom", "-format", "tsv"}} {
+		if _, err := runTrim("a\n", args...); err == nil {
+			t.Errorf("Expected an error for %v, got none", args)
+		}
+	}
+}
+
+// runTrim runs the trim program with the given input and arguments
+func runTrim(input string, args ...string) (string, error) {
+	cmd := exec.Command("go", append([]string{"run", "trim.go"}, args...)...)
+	var stdout bytes.Buffer
+	cmd.Stdin = bytes.NewReader([]byte(input))
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	return stdout.String(), nil
+}
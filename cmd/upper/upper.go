@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/jftuga/changecase"
 )
@@ -10,10 +11,36 @@ import (
 const pgmName string = "upper"
 
 func main() {
-	if len(os.Args) == 1 {
-		changecase.Usage(pgmName)
-		return
+	opts, args, err := changecase.ParseCommandArgs(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	input := strings.Join(args, " ")
+	if len(args) == 0 {
+		if !changecase.StdinIsPiped() {
+			changecase.Usage(pgmName)
+			return
+		}
+		data, err := changecase.ReadAllDecoded(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+			os.Exit(1)
+		}
+		input = string(data)
 	}
 
-	fmt.Printf("%v", changecase.Upper(os.Args[1:]))
+	output := changecase.Upper([]string{input})
+	if opts.Format == changecase.FormatText {
+		if opts.BOM {
+			output = changecase.BOM + output
+		}
+		fmt.Printf("%v", output)
+		return
+	}
+	fields := []changecase.Field{{Name: "input", Value: input}, {Name: "output", Value: output}}
+	if err := changecase.WriteRecord(os.Stdout, opts.Format, fields); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 }
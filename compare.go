@@ -0,0 +1,267 @@
+package changecase
+
+import (
+	"io"
+	"strings"
+	"unicode"
+)
+
+// TabWidth - the distance between tab stops when computing display columns
+const TabWidth = 8
+
+// DefaultContextRunes - the number of runes either side of a mismatch that
+// Compare returns when Options.ContextRunes is not set
+const DefaultContextRunes = 5
+
+// Options - how Compare matches two strings
+type Options struct {
+	CaseInsensitive bool
+	Whitespace      WhitespaceOptions
+	Skeleton        bool   // compare UTS #39 skeletons so confusables are equal
+	Masks           []Mask // replace volatile content with placeholders first
+	ContextRunes    int    // runes of context either side of a mismatch
+}
+
+// Remapped - report whether the compared runes may not line up one-to-one
+// with the original runes, so that positions must be mapped back
+func (opts Options) Remapped() bool {
+	return opts.Whitespace.Enabled() || opts.Skeleton || len(opts.Masks) > 0
+}
+
+// MismatchKind - how two strings differ at the first mismatch
+type MismatchKind int
+
+const (
+	MismatchNone        MismatchKind = iota // the strings match
+	MismatchRune                            // the runes at the mismatch differ
+	MismatchFirstEnded                      // the first string ends where the second goes on
+	MismatchSecondEnded                     // the second string ends where the first goes on
+)
+
+// String - the name of the kind, as used in machine-readable output
+func (kind MismatchKind) String() string {
+	switch kind {
+	case MismatchRune:
+		return "rune"
+	case MismatchFirstEnded:
+		return "first-ended"
+	case MismatchSecondEnded:
+		return "second-ended"
+	}
+	return "none"
+}
+
+// Position - a location within a string in every supported unit, all 1-based
+type Position struct {
+	Rune       int // rune position
+	Byte       int // byte position
+	Column     int // display column within the line, with tabs expanded
+	Line       int // line number
+	LineColumn int // rune column within the line
+}
+
+// Offset - the 0-based byte offset of the position
+func (pos Position) Offset() int {
+	return pos.Byte - 1
+}
+
+// Snippet - the text around a mismatch: At is the differing rune, or "" at
+// the end of the string, with up to ContextRunes runes Before and After it
+type Snippet struct {
+	Before string
+	At     string
+	After  string
+}
+
+// Result - the outcome of Compare. The positions and snippets are those of
+// the first mismatch within each original string; they are zero when the
+// strings match, and only differ between the strings when whitespace,
+// confusables or masks are being ignored.
+type Result struct {
+	Kind      MismatchKind
+	Position1 Position
+	Position2 Position
+	Context1  Snippet
+	Context2  Snippet
+}
+
+// Equal - report whether the strings matched
+func (res Result) Equal() bool {
+	return res.Kind == MismatchNone
+}
+
+// Compare - compare two strings rune by rune according to opts and describe
+// the first mismatch
+func Compare(a, b string, opts Options) Result {
+	var pos1, pos2 int
+	if !opts.Remapped() {
+		// strings.Reader never fails, so the error can be ignored
+		pos1, _ = CompareRuneReaders(strings.NewReader(a), strings.NewReader(b), opts.CaseInsensitive)
+		pos2 = pos1
+	} else {
+		runes1, origin1 := Prepare(a, opts)
+		runes2, origin2 := Prepare(b, opts)
+		i := 0
+		for i < len(runes1) && i < len(runes2) && runes1[i] == runes2[i] {
+			i++
+		}
+		if i < len(runes1) || i < len(runes2) {
+			pos1, pos2 = origin1[i]+1, origin2[i]+1
+		}
+	}
+	if pos1 == 0 {
+		return Result{}
+	}
+
+	context := opts.ContextRunes
+	if context <= 0 {
+		context = DefaultContextRunes
+	}
+	res := Result{
+		Kind:      MismatchRune,
+		Position1: Locate(a, pos1),
+		Position2: Locate(b, pos2),
+		Context1:  SnippetAt(a, pos1, context),
+		Context2:  SnippetAt(b, pos2, context),
+	}
+	switch {
+	case res.Context1.At == "":
+		res.Kind = MismatchFirstEnded
+	case res.Context2.At == "":
+		res.Kind = MismatchSecondEnded
+	}
+	return res
+}
+
+// Prepare - return the runes of s as Compare compares them, along with a map
+// from each of them to the 0-based index of the rune in s it came from, plus
+// a final entry of len([]rune(s)) for the end of the string
+func Prepare(s string, opts Options) ([]rune, []int) {
+	// masks see the original text, before any whitespace is removed
+	source := s
+	var maskOrigin []int
+	if len(opts.Masks) > 0 {
+		var masked []rune
+		masked, maskOrigin = ApplyMasks(s, opts.Masks)
+		source = string(masked)
+	}
+	runes, origin := NormalizeWhitespace(source, opts.Whitespace)
+	if maskOrigin != nil {
+		for i, idx := range origin {
+			origin[i] = maskOrigin[idx]
+		}
+	}
+	if opts.Skeleton {
+		var skeletonOrigin []int
+		runes, skeletonOrigin = SkeletonRunes(runes)
+		for i, idx := range skeletonOrigin {
+			skeletonOrigin[i] = origin[idx]
+		}
+		origin = skeletonOrigin
+	}
+	if opts.CaseInsensitive {
+		// fold rune by rune so the map back to the original stays valid
+		for i, r := range runes {
+			runes[i] = FoldRune(r)
+		}
+	}
+	return runes, origin
+}
+
+// originRange - map a half-open range of prepared runes back to the
+// corresponding range of original runes, using the map returned by Prepare
+func originRange(origin []int, start, end int) (int, int) {
+	if end == start {
+		return origin[start], origin[start]
+	}
+	return origin[start], origin[end-1] + 1
+}
+
+// FoldRune - the case folding of every case-insensitive comparison, whether
+// it works on strings or streams. It maps runes one to one, so positions in
+// the folded text match the original, and folds exactly as strings.ToLower.
+func FoldRune(r rune) rune {
+	return unicode.ToLower(r)
+}
+
+// CompareRuneReaders - read two rune streams in step and return the 1-based
+// position of the first mismatch, or 0 if they are identical. Only the
+// current rune of each stream is held, so inputs of any size are compared
+// in constant memory.
+func CompareRuneReaders(r1, r2 io.RuneReader, caseInsensitive bool) (int, error) {
+	for position := 1; ; position++ {
+		c1, _, err1 := r1.ReadRune()
+		if err1 != nil && err1 != io.EOF {
+			return 0, err1
+		}
+		c2, _, err2 := r2.ReadRune()
+		if err2 != nil && err2 != io.EOF {
+			return 0, err2
+		}
+		if err1 == io.EOF && err2 == io.EOF {
+			return 0, nil
+		}
+		if err1 == io.EOF || err2 == io.EOF {
+			return position, nil
+		}
+		if caseInsensitive {
+			c1, c2 = FoldRune(c1), FoldRune(c2)
+		}
+		if c1 != c2 {
+			return position, nil
+		}
+	}
+}
+
+// Locate - convert a 1-based rune position within s, which may be one past
+// the last rune, into every supported unit
+func Locate(s string, position int) Position {
+	pos := Position{Rune: position, Byte: len(s) + 1, Column: 1, Line: 1, LineColumn: 1}
+	i := 0
+	for offset, r := range s {
+		if i == position-1 {
+			pos.Byte = offset + 1
+			break
+		}
+		i++
+		switch r {
+		case '\n':
+			pos.Line++
+			pos.Column = 1
+			pos.LineColumn = 1
+			continue
+		case '\t':
+			pos.Column = ((pos.Column-1)/TabWidth+1)*TabWidth + 1
+		default:
+			pos.Column += RuneWidth(r)
+		}
+		pos.LineColumn++
+	}
+	return pos
+}
+
+// SnippetAt - return the rune at the 1-based position within s, which may be
+// one past the last rune, with up to context runes either side of it
+func SnippetAt(s string, position, context int) Snippet {
+	pos := position - 1
+	first := max(0, pos-context)
+	start, at, after, end := len(s), len(s), len(s), len(s)
+	i := 0
+	for offset := range s {
+		if i == first {
+			start = offset
+		}
+		if i == pos {
+			at = offset
+		}
+		if i == pos+1 {
+			after = offset
+		}
+		if i == pos+1+context {
+			end = offset
+			break
+		}
+		i++
+	}
+	return Snippet{Before: s[start:at], At: s[at:after], After: s[after:end]}
+}
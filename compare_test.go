@@ -0,0 +1,134 @@
+package changecase
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCompare tests the positions, kind and context of the first mismatch
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		str1     string
+		str2     string
+		opts     Options
+		kind     MismatchKind
+		position Position
+		context  Snippet
+	}{
+		{"identical", "hello", "hello", Options{}, MismatchNone, Position{}, Snippet{}},
+		{"rune", "hello", "hallo", Options{}, MismatchRune, Position{2, 2, 2, 1, 2}, Snippet{"h", "e", "llo"}},
+		{"first ended", "abc", "abcd", Options{}, MismatchFirstEnded, Position{4, 4, 4, 1, 4}, Snippet{"abc", "", ""}},
+		{"second ended", "abcd", "abc", Options{}, MismatchSecondEnded, Position{4, 4, 4, 1, 4}, Snippet{"abc", "d", ""}},
+		{"case-insensitive", "Hello", "hELLO", Options{CaseInsensitive: true}, MismatchNone, Position{}, Snippet{}},
+		{"multi-byte", "日本語です", "日本話です", Options{}, MismatchRune, Position{3, 7, 5, 1, 3}, Snippet{"日本", "語", "です"}},
+		{"second line", "ab\ncdXfghijklmn", "ab\ncdYfghijklmn", Options{}, MismatchRune, Position{6, 6, 3, 2, 3}, Snippet{"ab\ncd", "X", "fghij"}},
+		{"context", "0123456789", "01234x6789", Options{ContextRunes: 2}, MismatchRune, Position{6, 6, 6, 1, 6}, Snippet{"34", "5", "67"}},
+		{"whitespace", "a  b c", "a b d", Options{Whitespace: WhitespaceOptions{Collapse: true}}, MismatchRune, Position{6, 6, 6, 1, 6}, Snippet{"a  b ", "c", ""}},
+		{"mask", "id 10.0.0.1 ok", "id 10.0.0.2 OK", Options{Masks: []Mask{NamedMasks[3]}}, MismatchRune, Position{13, 13, 13, 1, 13}, Snippet{".0.1 ", "o", "k"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Compare(tt.str1, tt.str2, tt.opts)
+			if res.Kind != tt.kind {
+				t.Errorf("Expected kind %s, got %s", tt.kind, res.Kind)
+			}
+			if res.Equal() != (tt.kind == MismatchNone) {
+				t.Errorf("Expected Equal() to be %v", tt.kind == MismatchNone)
+			}
+			if res.Position1 != tt.position {
+				t.Errorf("Expected position %+v, got %+v", tt.position, res.Position1)
+			}
+			if res.Context1 != tt.context {
+				t.Errorf("Expected context %+v, got %+v", tt.context, res.Context1)
+			}
+		})
+	}
+}
+
+// TestCompareRuneReaders tests the streaming comparison
+func TestCompareRuneReaders(t *testing.T) {
+	long := strings.Repeat("x", 100000)
+	tests := []struct {
+		str1     string
+		str2     string
+		fold     bool
+		expected int
+	}{
+		{long, long, false, 0},
+		{long + "a", long + "b", false, 100001},
+		{long, long + "!", false, 100001},
+		{"ABC", "abc", true, 0},
+		{"", "", false, 0},
+		{"", "a", false, 1},
+	}
+
+	for _, tt := range tests {
+		got, err := CompareRuneReaders(strings.NewReader(tt.str1), strings.NewReader(tt.str2), tt.fold)
+		if err != nil || got != tt.expected {
+			t.Errorf("Expected %d, got %d (%v)", tt.expected, got, err)
+		}
+	}
+}
+
+// TestCaseFolding pins the case-insensitive string and stream comparisons to
+// each other and to strings.ToLower on runes with unusual case mappings
+func TestCaseFolding(t *testing.T) {
+	tests := []struct {
+		str1 string
+		str2 string
+	}{
+		{"İstanbul", "istanbul"},
+		{"İx", "i\u0307x"},
+		{"ſ", "s"},
+		{"ΣΑΣ", "σας"},
+		{"K", "k"},
+		{"ẞ", "ß"},
+		{"ǅ", "ǆ"},
+		{"a\xffB", "A\xfeb"},
+		{"MIXED case", "mixed CASE!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.str1, func(t *testing.T) {
+			// the position of the first mismatch after lowercasing both strings
+			lower1, lower2 := []rune(strings.ToLower(tt.str1)), []rune(strings.ToLower(tt.str2))
+			expected := 0
+			for i := 0; i < max(len(lower1), len(lower2)); i++ {
+				if i >= len(lower1) || i >= len(lower2) || lower1[i] != lower2[i] {
+					expected = i + 1
+					break
+				}
+			}
+
+			if got := Compare(tt.str1, tt.str2, Options{CaseInsensitive: true}).Position1.Rune; got != expected {
+				t.Errorf("Compare: expected %d, got %d", expected, got)
+			}
+			got, err := CompareRuneReaders(strings.NewReader(tt.str1), strings.NewReader(tt.str2), true)
+			if err != nil || got != expected {
+				t.Errorf("CompareRuneReaders: expected %d, got %d (%v)", expected, got, err)
+			}
+		})
+	}
+}
+
+// TestLocate tests the conversion of a rune position into every unit
+func TestLocate(t *testing.T) {
+	tests := []struct {
+		str      string
+		position int
+		expected Position
+	}{
+		{"café!", 5, Position{5, 6, 5, 1, 5}},
+		{"a\tb", 3, Position{3, 3, 9, 1, 3}},
+		{"ab\ncd", 5, Position{5, 5, 2, 2, 2}},
+		{"日本", 3, Position{3, 7, 5, 1, 3}},
+	}
+
+	for _, tt := range tests {
+		if got := Locate(tt.str, tt.position); got != tt.expected {
+			t.Errorf("Locate(%q, %d): expected %+v, got %+v", tt.str, tt.position, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package changecase
+
+import (
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed confusables.txt
+var confusablesData string
+
+// confusable - one entry of the confusables table
+type confusable struct {
+	target     []rune
+	sourceName string
+	targetName string
+}
+
+var (
+	confusablesOnce  sync.Once
+	confusablesTable map[rune]confusable
+)
+
+// loadConfusables - parse the embedded table on first use
+func loadConfusables() map[rune]confusable {
+	confusablesOnce.Do(func() {
+		confusablesTable = make(map[rune]confusable)
+		for _, line := range strings.Split(confusablesData, "\n") {
+			data, comment, _ := strings.Cut(line, "#")
+			fields := strings.Split(data, ";")
+			if len(fields) < 2 {
+				continue
+			}
+			source, err := parseCodePoints(fields[0])
+			if err != nil || len(source) != 1 {
+				continue
+			}
+			target, err := parseCodePoints(fields[1])
+			if err != nil {
+				continue
+			}
+
+			// the comment reads "( s → t ) SOURCE NAME → TARGET NAME"
+			entry := confusable{target: target}
+			if _, names, ok := strings.Cut(comment, ") "); ok {
+				sourceName, targetName, _ := strings.Cut(names, " → ")
+				entry.sourceName = strings.TrimSpace(sourceName)
+				entry.targetName = strings.TrimSpace(targetName)
+			}
+			confusablesTable[source[0]] = entry
+		}
+	})
+	return confusablesTable
+}
+
+// parseCodePoints - convert space separated hexadecimal code points to runes
+func parseCodePoints(field string) ([]rune, error) {
+	var runes []rune
+	for _, hex := range strings.Fields(field) {
+		cp, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return nil, err
+		}
+		runes = append(runes, rune(cp))
+	}
+	if len(runes) == 0 {
+		return nil, fmt.Errorf("no code points in %q", field)
+	}
+	return runes, nil
+}
+
+// Skeleton - return the UTS #39 skeleton of s, in which every confusable
+// rune is replaced by its prototype, so that two strings that look alike
+// have the same skeleton. Unlike the full algorithm, no NFD normalization
+// is applied before and after the mapping.
+func Skeleton(s string) string {
+	runes, _ := SkeletonRunes([]rune(s))
+	return string(runes)
+}
+
+// SkeletonRunes - map runes to their skeleton, also returning the index in
+// runes that each skeleton rune came from, plus a final entry of len(runes)
+func SkeletonRunes(runes []rune) ([]rune, []int) {
+	table := loadConfusables()
+	out := make([]rune, 0, len(runes))
+	origin := make([]int, 0, len(runes)+1)
+	for i, r := range runes {
+		if entry, ok := table[r]; ok {
+			for _, t := range entry.target {
+				out = append(out, t)
+				origin = append(origin, i)
+			}
+			continue
+		}
+		out = append(out, r)
+		origin = append(origin, i)
+	}
+	return out, append(origin, len(runes))
+}
+
+// Confusable - report whether two different runes look alike, which is the
+// case when they share the same skeleton
+func Confusable(a, b rune) bool {
+	if a == b {
+		return false
+	}
+	s1, _ := SkeletonRunes([]rune{a})
+	s2, _ := SkeletonRunes([]rune{b})
+	return string(s1) == string(s2)
+}
+
+// RuneName - return the Unicode name of r when it appears in the confusables
+// table, either as a source or as a target, or "" when it is unknown
+func RuneName(r rune) string {
+	table := loadConfusables()
+	if entry, ok := table[r]; ok && entry.sourceName != "" {
+		return entry.sourceName
+	}
+	for _, entry := range table {
+		if len(entry.target) == 1 && entry.target[0] == r && entry.targetName != "" {
+			return entry.targetName
+		}
+	}
+	return ""
+}
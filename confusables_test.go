@@ -0,0 +1,62 @@
+package changecase
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestSkeleton tests that look-alike strings share a skeleton
+func TestSkeleton(t *testing.T) {
+	tests := []struct {
+		name  string
+		str1  string
+		str2  string
+		equal bool
+	}{
+		{"cyrillic a", "p\u0430ypal", "paypal", true},
+		{"greek capitals", "ΑΒΕ", "ABE", true},
+		{"digit zero", "C0NFIG", "CONFIG", true},
+		{"fullwidth", "ａｂｃ", "abc", true},
+		{"nbsp", "a\u00a0b", "a b", true},
+		{"different letters", "abc", "abd", false},
+		{"case matters", "ABC", "abc", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Skeleton(tt.str1) == Skeleton(tt.str2); got != tt.equal {
+				t.Errorf("Expected skeletons equal to be %v, got %q and %q", tt.equal, Skeleton(tt.str1), Skeleton(tt.str2))
+			}
+		})
+	}
+}
+
+// TestSkeletonRunes tests the map from skeleton runes back to the input
+func TestSkeletonRunes(t *testing.T) {
+	runes, origin := SkeletonRunes([]rune("x\u0430y"))
+	if string(runes) != "xay" {
+		t.Errorf("Expected %q, got %q", "xay", string(runes))
+	}
+	if expected := []int{0, 1, 2, 3}; !reflect.DeepEqual(origin, expected) {
+		t.Errorf("Expected origin %v, got %v", expected, origin)
+	}
+}
+
+// TestConfusable tests rune pairs and the names read from the table
+func TestConfusable(t *testing.T) {
+	if !Confusable('\u0430', 'a') {
+		t.Errorf("Expected U+0430 and U+0061 to be confusable")
+	}
+	if Confusable('a', 'a') {
+		t.Errorf("Expected identical runes not to be reported as confusable")
+	}
+	if Confusable('a', 'b') {
+		t.Errorf("Expected U+0061 and U+0062 not to be confusable")
+	}
+	if name := RuneName('\u0430'); name != "CYRILLIC SMALL LETTER A" {
+		t.Errorf("Expected CYRILLIC SMALL LETTER A, got %q", name)
+	}
+	if name := RuneName('a'); name != "LATIN SMALL LETTER A" {
+		t.Errorf("Expected LATIN SMALL LETTER A, got %q", name)
+	}
+}
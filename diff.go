@@ -0,0 +1,276 @@
+package changecase
+
+// DiffOp - the kind of edit described by a DiffRange
+type DiffOp int
+
+const (
+	DiffEqual DiffOp = iota
+	DiffInsert
+	DiffDelete
+	DiffChange
+)
+
+// String - the single-letter code used by the classic diff "normal" format
+func (op DiffOp) String() string {
+	switch op {
+	case DiffInsert:
+		return "a"
+	case DiffDelete:
+		return "d"
+	case DiffChange:
+		return "c"
+	}
+	return "="
+}
+
+// DiffRange - a run of elements that are equal, inserted, deleted or changed.
+// Start1/End1 index the first sequence and Start2/End2 the second, both as
+// 0-based half-open ranges.
+type DiffRange struct {
+	Op     DiffOp
+	Start1 int
+	End1   int
+	Start2 int
+	End2   int
+}
+
+// DiffStats - summary numbers derived from an edit script
+type DiffStats struct {
+	Distance   int     // number of inserted plus deleted elements
+	Common     int     // length of the longest common subsequence
+	Similarity float64 // 2*Common / (len1+len2), as a percentage
+}
+
+// DiffRunes - return the edit script that turns a into b, computed with
+// Myers' O(ND) algorithm. Adjacent deletions and insertions are merged
+// into a single DiffChange range.
+func DiffRunes(a, b []rune) []DiffRange {
+	return mergeChanges(myers(a, b))
+}
+
+// DiffLines - return the edit script that turns the lines of a into the
+// lines of b. Unlike DiffRunes, deletions and insertions are kept apart so
+// that a unified diff can list the removed lines before the added ones.
+func DiffLines(a, b []string) []DiffRange {
+	return myers(a, b)
+}
+
+// Differences - return only the non-equal ranges of an edit script
+func Differences(script []DiffRange) []DiffRange {
+	var diffs []DiffRange
+	for _, r := range script {
+		if r.Op != DiffEqual {
+			diffs = append(diffs, r)
+		}
+	}
+	return diffs
+}
+
+// Stats - return the edit distance and similarity of an edit script
+func Stats(script []DiffRange) DiffStats {
+	var stats DiffStats
+	len1, len2 := 0, 0
+	for _, r := range script {
+		len1 += r.End1 - r.Start1
+		len2 += r.End2 - r.Start2
+		if r.Op == DiffEqual {
+			stats.Common += r.End1 - r.Start1
+		} else {
+			stats.Distance += (r.End1 - r.Start1) + (r.End2 - r.Start2)
+		}
+	}
+	if len1+len2 == 0 {
+		stats.Similarity = 100
+	} else {
+		stats.Similarity = 200 * float64(stats.Common) / float64(len1+len2)
+	}
+	return stats
+}
+
+// AllDifferences - return every differing range between a and b, once
+// prepared with the case, whitespace, mask and skeleton options, in runes of
+// the original strings, along with the statistics of the prepared strings
+func AllDifferences(a, b string, opts Options) ([]DiffRange, DiffStats) {
+	cmp1, origin1 := Prepare(a, opts)
+	cmp2, origin2 := Prepare(b, opts)
+	script := DiffRunes(cmp1, cmp2)
+
+	diffs := Differences(script)
+	for i, d := range diffs {
+		diffs[i].Start1, diffs[i].End1 = originRange(origin1, d.Start1, d.End1)
+		diffs[i].Start2, diffs[i].End2 = originRange(origin2, d.Start2, d.End2)
+	}
+	return diffs, Stats(script)
+}
+
+// myers - compute a shortest edit script between a and b as a list of
+// equal, insert and delete ranges. This is the linear space refinement of
+// Myers' algorithm: the middle of a shortest path is found by searching from
+// both ends at once, and the two halves either side of it are diffed in
+// turn, so memory use is O(N+M) however many differences there are.
+func myers[T comparable](a, b []T) []DiffRange {
+	limit := (len(a) + len(b) + 1) / 2
+	df := &differ[T]{
+		a:        a,
+		b:        b,
+		forward:  make([]int, 2*limit+3),
+		backward: make([]int, 2*limit+3),
+	}
+	df.diff(0, len(a), 0, len(b))
+	return df.script
+}
+
+// differ - the state of a linear space Myers diff. The furthest reaching
+// paths of each search are shared by every step, as only one runs at a time.
+type differ[T comparable] struct {
+	a, b              []T
+	forward, backward []int
+	script            []DiffRange
+}
+
+// diff - append the edit script that turns a[lo1:hi1] into b[lo2:hi2]
+func (df *differ[T]) diff(lo1, hi1, lo2, hi2 int) {
+	prefix := 0
+	for lo1+prefix < hi1 && lo2+prefix < hi2 && df.a[lo1+prefix] == df.b[lo2+prefix] {
+		prefix++
+	}
+	df.add(DiffEqual, prefix)
+	lo1, lo2 = lo1+prefix, lo2+prefix
+
+	suffix := 0
+	for hi1-suffix > lo1 && hi2-suffix > lo2 && df.a[hi1-suffix-1] == df.b[hi2-suffix-1] {
+		suffix++
+	}
+	hi1, hi2 = hi1-suffix, hi2-suffix
+
+	switch {
+	case lo1 == hi1:
+		df.add(DiffInsert, hi2-lo2)
+	case lo2 == hi2:
+		df.add(DiffDelete, hi1-lo1)
+	default:
+		// with the common ends removed there are at least two edits, so
+		// both halves are smaller than the whole
+		if x, y, ok := df.middle(lo1, hi1, lo2, hi2); ok {
+			df.diff(lo1, x, lo2, y)
+			df.diff(x, hi1, y, hi2)
+		} else {
+			df.add(DiffDelete, hi1-lo1)
+			df.add(DiffInsert, hi2-lo2)
+		}
+	}
+	df.add(DiffEqual, suffix)
+}
+
+// middle - return a point on a shortest path from (lo1, lo2) to (hi1, hi2),
+// where the furthest reaching paths from either end first overlap
+func (df *differ[T]) middle(lo1, hi1, lo2, hi2 int) (int, int, bool) {
+	n, m := hi1-lo1, hi2-lo2
+	limit := (n + m + 1) / 2
+	offset := limit + 1
+	forward, backward := df.forward[:2*limit+3], df.backward[:2*limit+3]
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+
+	// the paths from either end can only meet on the same diagonal after a
+	// forward step when the difference in lengths is odd, and after a
+	// backward step when it is even
+	delta := n - m
+	odd := delta%2 != 0
+	// diagonals whose paths have left the grid are not searched again
+	start1, end1, start2, end2 := 0, 0, 0, 0
+	for d := 0; d <= limit; d++ {
+		for k := -d + start1; k <= d-end1; k += 2 {
+			var x int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && df.a[lo1+x] == df.b[lo2+y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			switch back := offset + delta - k; {
+			case x > n:
+				end1 += 2
+			case y > m:
+				start1 += 2
+			case odd && back >= 0 && back < len(backward) && backward[back] >= 0 && x >= n-backward[back]:
+				return lo1 + x, lo2 + y, true
+			}
+		}
+		for k := -d + start2; k <= d-end2; k += 2 {
+			var x int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && df.a[hi1-x-1] == df.b[hi2-y-1] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+			switch front := offset + delta - k; {
+			case x > n:
+				end2 += 2
+			case y > m:
+				start2 += 2
+			case !odd && front >= 0 && front < len(forward) && forward[front] >= 0 && forward[front] >= n-x:
+				fx := forward[front]
+				return lo1 + fx, lo2 + fx - (delta - k), true
+			}
+		}
+	}
+	// not reached, as the paths always meet within limit steps
+	return 0, 0, false
+}
+
+// add - append count elements of the given kind to the script, extending its
+// last range when that is of the same kind
+func (df *differ[T]) add(op DiffOp, count int) {
+	if count == 0 {
+		return
+	}
+	i, j := 0, 0
+	last := len(df.script) - 1
+	if last >= 0 {
+		i, j = df.script[last].End1, df.script[last].End2
+	}
+	di, dj := count, count
+	switch op {
+	case DiffInsert:
+		di = 0
+	case DiffDelete:
+		dj = 0
+	}
+	if last >= 0 && df.script[last].Op == op {
+		df.script[last].End1 += di
+		df.script[last].End2 += dj
+		return
+	}
+	df.script = append(df.script, DiffRange{Op: op, Start1: i, End1: i + di, Start2: j, End2: j + dj})
+}
+
+// mergeChanges - fold each run of adjacent deletes and inserts into a single
+// range, which is a DiffChange when both sides are non-empty
+func mergeChanges(script []DiffRange) []DiffRange {
+	var merged []DiffRange
+	for _, r := range script {
+		last := len(merged) - 1
+		if r.Op != DiffEqual && last >= 0 && merged[last].Op != DiffEqual {
+			merged[last].End1 = r.End1
+			merged[last].End2 = r.End2
+			merged[last].Op = DiffChange
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
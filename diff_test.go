@@ -0,0 +1,188 @@
+package changecase
+
+import (
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// TestDiffRunes tests the Myers edit script against known inputs
+func TestDiffRunes(t *testing.T) {
+	tests := []struct {
+		name     string
+		str1     string
+		str2     string
+		expected []DiffRange
+	}{
+		{"identical", "abc", "abc", []DiffRange{{DiffEqual, 0, 3, 0, 3}}},
+		{"both empty", "", "", nil},
+		{"insertion", "abc", "abXc", []DiffRange{
+			{DiffEqual, 0, 2, 0, 2},
+			{DiffInsert, 2, 2, 2, 3},
+			{DiffEqual, 2, 3, 3, 4},
+		}},
+		{"deletion", "abXc", "abc", []DiffRange{
+			{DiffEqual, 0, 2, 0, 2},
+			{DiffDelete, 2, 3, 2, 2},
+			{DiffEqual, 3, 4, 2, 3},
+		}},
+		{"change", "hello", "hallo", []DiffRange{
+			{DiffEqual, 0, 1, 0, 1},
+			{DiffChange, 1, 2, 1, 2},
+			{DiffEqual, 2, 5, 2, 5},
+		}},
+		{"multi-byte unicode", "日本語", "日本話", []DiffRange{
+			{DiffEqual, 0, 2, 0, 2},
+			{DiffChange, 2, 3, 2, 3},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffRunes([]rune(tt.str1), []rune(tt.str2))
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestStats tests the edit distance and similarity derived from a diff
+func TestStats(t *testing.T) {
+	tests := []struct {
+		name       string
+		str1       string
+		str2       string
+		distance   int
+		similarity float64
+	}{
+		{"identical", "hello", "hello", 0, 100},
+		{"both empty", "", "", 0, 100},
+		{"one empty", "abc", "", 3, 0},
+		{"kitten sitting", "kitten", "sitting", 5, 800.0 / 13},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := Stats(DiffRunes([]rune(tt.str1), []rune(tt.str2)))
+			if stats.Distance != tt.distance {
+				t.Errorf("Expected distance %d, got %d", tt.distance, stats.Distance)
+			}
+			if stats.Similarity != tt.similarity {
+				t.Errorf("Expected similarity %f, got %f", tt.similarity, stats.Similarity)
+			}
+		})
+	}
+}
+
+// TestDiffLines tests the line-level edit script
+func TestDiffLines(t *testing.T) {
+	got := DiffLines([]string{"a", "b", "c"}, []string{"a", "x", "c", "d"})
+	expected := []DiffRange{
+		{DiffEqual, 0, 1, 0, 1},
+		{DiffDelete, 1, 2, 1, 1},
+		{DiffInsert, 2, 2, 1, 2},
+		{DiffEqual, 2, 3, 2, 3},
+		{DiffInsert, 3, 3, 3, 4},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+// TestDiffShortest checks that the edit script of many random inputs turns
+// the first into the second with the fewest edits, as found by dynamic
+// programming
+func TestDiffShortest(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := func() []rune {
+		runes := make([]rune, rng.Intn(30))
+		for i := range runes {
+			runes[i] = rune('a' + rng.Intn(3))
+		}
+		return runes
+	}
+	for n := 0; n < 2000; n++ {
+		a, b := random(), random()
+		script := DiffRunes(a, b)
+		var rebuilt []rune
+		for _, r := range script {
+			if r.Op == DiffEqual && string(a[r.Start1:r.End1]) != string(b[r.Start2:r.End2]) {
+				t.Fatalf("%q -> %q: unequal range %v", string(a), string(b), r)
+			}
+			rebuilt = append(rebuilt, b[r.Start2:r.End2]...)
+		}
+		if string(rebuilt) != string(b) {
+			t.Fatalf("%q -> %q: script %v rebuilds %q", string(a), string(b), script, string(rebuilt))
+		}
+		if got, expected := Stats(script).Distance, len(a)+len(b)-2*lcsLength(a, b); got != expected {
+			t.Fatalf("%q -> %q: expected distance %d, got %d", string(a), string(b), expected, got)
+		}
+	}
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b
+func lcsLength(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				curr[j+1] = prev[j] + 1
+			} else {
+				curr[j+1] = max(prev[j+1], curr[j])
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// TestDiffLinesLarge diffs inputs with thousands of differences, which needs
+// memory for every step of the search unless it is done in linear space
+func TestDiffLinesLarge(t *testing.T) {
+	a := make([]string, 100000)
+	b := make([]string, 100000)
+	for i := range a {
+		a[i] = strconv.Itoa(i)
+		b[i] = a[i]
+		if i%50 == 0 {
+			b[i] = "changed"
+		}
+	}
+	stats := Stats(DiffLines(a, b))
+	if stats.Distance != 2*len(a)/50 {
+		t.Errorf("Expected distance %d, got %d", 2*len(a)/50, stats.Distance)
+	}
+}
+
+// TestAllDifferences tests that differences are found after the options
+// are applied and reported in runes of the original strings
+func TestAllDifferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		str1     string
+		str2     string
+		opts     Options
+		expected []DiffRange
+		distance int
+	}{
+		{"identical", "abc", "abc", Options{}, nil, 0},
+		{"two changes", "hello world", "hallo wurld", Options{},
+			[]DiffRange{{DiffChange, 1, 2, 1, 2}, {DiffChange, 7, 8, 7, 8}}, 4},
+		{"case insensitive", "Hello", "hELLo", Options{CaseInsensitive: true}, nil, 0},
+		{"after collapsed whitespace", "a   bc", "a bX", Options{Whitespace: WhitespaceOptions{Collapse: true}},
+			[]DiffRange{{DiffChange, 5, 6, 3, 4}}, 2},
+		{"insertion at the end", "ab", "abcd", Options{}, []DiffRange{{DiffInsert, 2, 2, 2, 4}}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs, stats := AllDifferences(tt.str1, tt.str2, tt.opts)
+			if !reflect.DeepEqual(diffs, tt.expected) || stats.Distance != tt.distance {
+				t.Errorf("Expected %v (distance %d), got %v (distance %d)", tt.expected, tt.distance, diffs, stats.Distance)
+			}
+		})
+	}
+}
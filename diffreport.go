@@ -0,0 +1,233 @@
+package changecase
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Output modes of DiffReport
+const (
+	DiffUnified    = "unified"      // hunks of removed and added lines, as diff -u prints
+	DiffSideBySide = "side-by-side" // both inputs in two columns
+)
+
+// diffContext is the number of unchanged lines shown around each unified diff hunk
+const diffContext = 3
+
+// defaultDiffWidth is the width of side-by-side output when neither the
+// requested width nor the COLUMNS environment variable is set
+const defaultDiffWidth = 130
+
+// DiffInput - the lines of one side of a line diff, under a label
+type DiffInput struct {
+	Label string
+	LineText
+}
+
+// DiffWidth - return the width of side-by-side output: width if set, else
+// the COLUMNS environment variable, else 130
+func DiffWidth(width int) int {
+	if width > 0 {
+		return width
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return defaultDiffWidth
+}
+
+// hunkRange - format one side of a hunk header as diff does: "start,count",
+// with the count left out when it is 1
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// wholeLine - return a line as a single segment
+func wholeLine(line string, changed bool) []Segment {
+	return []Segment{{Runes: []rune(line), Changed: changed}}
+}
+
+// paintSegments - return the segments as raw text, colored with base and the
+// changed runs with emph when colors are enabled
+func (p Palette) paintSegments(segs []Segment, base, emph string) string {
+	var b strings.Builder
+	for _, seg := range segs {
+		color := base
+		if seg.Changed {
+			color = emph
+		}
+		b.WriteString(p.paint(color, string(seg.Runes)))
+	}
+	return b.String()
+}
+
+// UnifiedDiff - return a unified diff of two inputs compared into ops by
+// CompareLines, with intra-line highlighting of paired changed lines when
+// colors are enabled
+func (p Palette) UnifiedDiff(a, b DiffInput, ops []LineOp) string {
+	var sb strings.Builder
+	sb.WriteString(p.paint(ansiHeader, "--- "+a.Label) + "\n")
+	sb.WriteString(p.paint(ansiHeader, "+++ "+b.Label) + "\n")
+
+	noNewline := func(in DiffInput, idx int) {
+		if in.NoEOL && idx == len(in.Lines)-1 {
+			sb.WriteString("\\ No newline at end of file\n")
+		}
+	}
+
+	for _, hunk := range GroupHunks(ops, diffContext) {
+		sb.WriteString(p.paint(ansiHunk, fmt.Sprintf("@@ -%s +%s @@", hunkRange(hunk.Start1, hunk.Count1), hunkRange(hunk.Start2, hunk.Count2))) + "\n")
+
+		for k := hunk.Start; k < hunk.End; {
+			if ops[k].Kind == ' ' {
+				fmt.Fprintf(&sb, " %s\n", a.Lines[ops[k].Line1])
+				noNewline(a, ops[k].Line1)
+				k++
+				continue
+			}
+
+			end, mid := ChangeBlock(ops, k)
+			removed, added := ops[k:mid], ops[mid:end]
+			segs1 := make([][]Segment, len(removed))
+			segs2 := make([][]Segment, len(added))
+			for n := range removed {
+				segs1[n] = wholeLine(a.Lines[removed[n].Line1], false)
+			}
+			for n := range added {
+				segs2[n] = wholeLine(b.Lines[added[n].Line2], false)
+			}
+			for n := 0; n < len(removed) && n < len(added); n++ {
+				segs1[n], segs2[n] = IntraLineSegments(a.Lines[removed[n].Line1], b.Lines[added[n].Line2])
+			}
+
+			for n, op := range removed {
+				sb.WriteString(p.paint(ansiRemoved, "-") + p.paintSegments(segs1[n], ansiRemoved, ansiRemovedEmph) + "\n")
+				noNewline(a, op.Line1)
+			}
+			for n, op := range added {
+				sb.WriteString(p.paint(ansiAdded, "+") + p.paintSegments(segs2[n], ansiAdded, ansiAddedEmph) + "\n")
+				noNewline(b, op.Line2)
+			}
+			k = end
+		}
+	}
+	return sb.String()
+}
+
+// piece is a displayable fragment of a side-by-side column
+type piece struct {
+	text  string
+	width int
+	color string
+}
+
+// renderColumn - lay out segments in exactly width display cells, escaping
+// invisible runes, marking changed runs with color (or with brackets when
+// colors are disabled) and truncating with "…" when the text is too wide
+func (p Palette) renderColumn(segs []Segment, width int, base, emph string) string {
+	var pieces []piece
+	total := 0
+	add := func(text string, w int, color string) {
+		pieces = append(pieces, piece{text, w, color})
+		total += w
+	}
+	for _, seg := range segs {
+		color := base
+		if seg.Changed {
+			color = emph
+			if !p.Enabled {
+				add("[", 1, "")
+			}
+		}
+		for _, r := range seg.Runes {
+			if esc := escapeRune(r); esc != "" {
+				add(esc, len(esc), color)
+			} else {
+				add(string(r), RuneWidth(r), color)
+			}
+		}
+		if seg.Changed && !p.Enabled {
+			add("]", 1, "")
+		}
+	}
+
+	var b strings.Builder
+	used := 0
+	for _, pc := range pieces {
+		if total > width && used+pc.width > width-1 {
+			b.WriteString("…")
+			used++
+			break
+		}
+		b.WriteString(p.paint(pc.color, pc.text))
+		used += pc.width
+	}
+	b.WriteString(strings.Repeat(" ", max(0, width-used)))
+	return b.String()
+}
+
+// SideBySideDiff - return two inputs compared into ops by CompareLines in two
+// columns of a total width, marking changed lines with "|", removed lines
+// with "<" and added lines with ">"
+func (p Palette) SideBySideDiff(a, b DiffInput, ops []LineOp, width int) string {
+	var sb strings.Builder
+	column := max(10, (width-3)/2)
+	blank := strings.Repeat(" ", column)
+
+	for k := 0; k < len(ops); {
+		if ops[k].Kind == ' ' {
+			line := a.Lines[ops[k].Line1]
+			fmt.Fprintf(&sb, "%s   %s\n", p.renderColumn(wholeLine(line, false), column, "", ""),
+				strings.TrimRight(p.renderColumn(wholeLine(b.Lines[ops[k].Line2], false), column, "", ""), " "))
+			k++
+			continue
+		}
+
+		end, mid := ChangeBlock(ops, k)
+		removed, added := ops[k:mid], ops[mid:end]
+		for n := 0; n < len(removed) || n < len(added); n++ {
+			switch {
+			case n < len(removed) && n < len(added):
+				segs1, segs2 := IntraLineSegments(a.Lines[removed[n].Line1], b.Lines[added[n].Line2])
+				fmt.Fprintf(&sb, "%s %s %s\n", p.renderColumn(segs1, column, ansiRemoved, ansiRemovedEmph),
+					p.paint(ansiHunk, "|"), strings.TrimRight(p.renderColumn(segs2, column, ansiAdded, ansiAddedEmph), " "))
+			case n < len(removed):
+				fmt.Fprintf(&sb, "%s %s\n", p.renderColumn(wholeLine(a.Lines[removed[n].Line1], true), column, ansiRemoved, ansiRemoved),
+					p.paint(ansiHunk, "<"))
+			default:
+				fmt.Fprintf(&sb, "%s %s %s\n", blank, p.paint(ansiHunk, ">"),
+					strings.TrimRight(p.renderColumn(wholeLine(b.Lines[added[n].Line2], true), column, ansiAdded, ansiAdded), " "))
+			}
+		}
+		k = end
+	}
+	return sb.String()
+}
+
+// DiffReport - compare two inputs line by line and return a unified or
+// side-by-side diff of them, followed in verbose mode by a count of the
+// changed lines, and whether they match
+func (p Palette) DiffReport(a, b DiffInput, mode string, opts Options, width int, verbose bool) (string, bool) {
+	ops := CompareLines(a.LineText, b.LineText, opts)
+	removed, added := CountLineChanges(ops)
+
+	var sb strings.Builder
+	if mode == DiffSideBySide {
+		sb.WriteString(p.SideBySideDiff(a, b, ops, width))
+	} else if removed+added > 0 {
+		sb.WriteString(p.UnifiedDiff(a, b, ops))
+	}
+	if verbose {
+		if removed+added == 0 {
+			sb.WriteString("Inputs match exactly\n")
+		} else {
+			fmt.Fprintf(&sb, "Inputs differ: %d line(s) removed, %d line(s) added\n", removed, added)
+		}
+	}
+	return sb.String(), removed+added == 0
+}
@@ -0,0 +1,107 @@
+package changecase
+
+import "testing"
+
+// diffInputs returns the two sides of the line diffs used by the tests
+func diffInputs() (DiffInput, DiffInput, []LineOp) {
+	a := DiffInput{"a", SplitLines("one\ntwo\nthree\n", false)}
+	b := DiffInput{"b", SplitLines("one\ntoo\nthree\nfour", false)}
+	return a, b, CompareLines(a.LineText, b.LineText, Options{})
+}
+
+// TestDiffWidth tests choosing the width of side-by-side output
+func TestDiffWidth(t *testing.T) {
+	t.Setenv("COLUMNS", "100")
+	if got := DiffWidth(80); got != 80 {
+		t.Errorf("Expected 80, got %d", got)
+	}
+	if got := DiffWidth(0); got != 100 {
+		t.Errorf("Expected 100, got %d", got)
+	}
+	t.Setenv("COLUMNS", "wide")
+	if got := DiffWidth(0); got != defaultDiffWidth {
+		t.Errorf("Expected %d, got %d", defaultDiffWidth, got)
+	}
+}
+
+// TestUnifiedDiff tests the hunks of a unified diff, with and without colors
+func TestUnifiedDiff(t *testing.T) {
+	a, b, ops := diffInputs()
+	tests := []struct {
+		name     string
+		colors   bool
+		expected string
+	}{
+		{"plain", false, "--- a\n+++ b\n@@ -1,3 +1,4 @@\n one\n-two\n+too\n three\n+four\n\\ No newline at end of file\n"},
+		{"colors", true, "\x1b[1m--- a\x1b[0m\n\x1b[1m+++ b\x1b[0m\n\x1b[36m@@ -1,3 +1,4 @@\x1b[0m\n one\n" +
+			"\x1b[31m-\x1b[0m\x1b[31mt\x1b[0m\x1b[7;31mw\x1b[0m\x1b[31mo\x1b[0m\n" +
+			"\x1b[32m+\x1b[0m\x1b[32mt\x1b[0m\x1b[7;32mo\x1b[0m\x1b[32mo\x1b[0m\n three\n" +
+			"\x1b[32m+\x1b[0m\x1b[32mfour\x1b[0m\n\\ No newline at end of file\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Palette{Enabled: tt.colors}).UnifiedDiff(a, b, ops); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestSideBySideDiff tests laying out two inputs in columns
+func TestSideBySideDiff(t *testing.T) {
+	a, b, ops := diffInputs()
+	tests := []struct {
+		name     string
+		colors   bool
+		expected string
+	}{
+		{"plain", false, "one          one\nt[w]o      | t[o]o\nthree        three\n           > [four]\n"},
+		{"colors", true, "one          one\n" +
+			"\x1b[31mt\x1b[0m\x1b[7;31mw\x1b[0m\x1b[31mo\x1b[0m        \x1b[36m|\x1b[0m \x1b[32mt\x1b[0m\x1b[7;32mo\x1b[0m\x1b[32mo\x1b[0m\n" +
+			"three        three\n" +
+			"           \x1b[36m>\x1b[0m \x1b[32mf\x1b[0m\x1b[32mo\x1b[0m\x1b[32mu\x1b[0m\x1b[32mr\x1b[0m\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Palette{Enabled: tt.colors}).SideBySideDiff(a, b, ops, 23); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestRenderColumn tests fitting a line into a column
+func TestRenderColumn(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected string
+	}{
+		{"padded", "abc", "abc       "},
+		{"truncated", "abcdefghijkl", "abcdefghi…"},
+		{"escaped", "a\tb", `a\tb` + "      "},
+		{"wide", "漢字漢字漢字", "漢字漢字… "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Palette{}).renderColumn(wholeLine(tt.line, false), 10, "", ""); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestDiffReport tests the summary of a line diff
+func TestDiffReport(t *testing.T) {
+	a, b, _ := diffInputs()
+	if got, equal := (Palette{}).DiffReport(a, a, DiffUnified, Options{}, 80, true); !equal || got != "Inputs match exactly\n" {
+		t.Errorf("Expected a match, got %v and %q", equal, got)
+	}
+	got, equal := (Palette{}).DiffReport(a, b, DiffUnified, Options{}, 80, true)
+	if expected := "\\ No newline at end of file\nInputs differ: 1 line(s) removed, 2 line(s) added\n"; equal || len(got) < len(expected) || got[len(got)-len(expected):] != expected {
+		t.Errorf("Expected the diff and its summary, got %v and %q", equal, got)
+	}
+}
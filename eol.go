@@ -0,0 +1,164 @@
+package changecase
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Line ending styles reported by LineEndings.Style
+const (
+	EOLNone  = "none"  // no line endings at all
+	EOLLF    = "lf"    // only "\n"
+	EOLCRLF  = "crlf"  // only "\r\n"
+	EOLCR    = "cr"    // only a lone "\r"
+	EOLMixed = "mixed" // more than one kind
+)
+
+// LineEndings - how many of each kind of line ending some input holds
+type LineEndings struct {
+	LF   int
+	CRLF int
+	CR   int
+}
+
+// Style - the line ending style: EOLNone, EOLLF, EOLCRLF, EOLCR or EOLMixed
+func (counts LineEndings) Style() string {
+	switch {
+	case counts.Mixed():
+		return EOLMixed
+	case counts.LF > 0:
+		return EOLLF
+	case counts.CRLF > 0:
+		return EOLCRLF
+	case counts.CR > 0:
+		return EOLCR
+	}
+	return EOLNone
+}
+
+// Mixed - report whether more than one kind of line ending is used
+func (counts LineEndings) Mixed() bool {
+	kinds := 0
+	for _, n := range []int{counts.LF, counts.CRLF, counts.CR} {
+		if n > 0 {
+			kinds++
+		}
+	}
+	return kinds > 1
+}
+
+// ParseLineEnding - return the line ending named lf or crlf
+func ParseLineEnding(name string) (string, error) {
+	switch name {
+	case EOLLF:
+		return "\n", nil
+	case EOLCRLF:
+		return "\r\n", nil
+	}
+	return "", fmt.Errorf("unknown line ending %q (use lf or crlf)", name)
+}
+
+// LineEndingWriter - an io.Writer that counts the line endings of what is
+// written to it and passes it on to another writer, converting every line
+// ending on the way when To is set. Input may be written in blocks of any
+// size: a "\r" at the end of a block is held back until the next block shows
+// whether it starts a "\r\n". Close must be called after the last block.
+type LineEndingWriter struct {
+	Counts LineEndings
+	To     string // "" to keep line endings as they are, or "\n" or "\r\n"
+
+	w         io.Writer
+	pendingCR bool
+	out       []byte
+}
+
+// NewLineEndingWriter - return a LineEndingWriter that writes to w, converting
+// line endings to "\n" or "\r\n", or keeping them when to is ""
+func NewLineEndingWriter(w io.Writer, to string) *LineEndingWriter {
+	return &LineEndingWriter{To: to, w: w}
+}
+
+// Write - count and convert the line endings of p and write the result
+func (lw *LineEndingWriter) Write(p []byte) (int, error) {
+	out := lw.out[:0]
+	rest := p
+	if lw.pendingCR && len(rest) > 0 {
+		lw.pendingCR = false
+		if rest[0] == '\n' {
+			out = lw.ending(out, "\r\n")
+			rest = rest[1:]
+		} else {
+			out = lw.ending(out, "\r")
+		}
+	}
+	for len(rest) > 0 {
+		i := bytes.IndexAny(rest, "\r\n")
+		if i < 0 {
+			out = append(out, rest...)
+			break
+		}
+		out = append(out, rest[:i]...)
+		switch {
+		case rest[i] == '\n':
+			out = lw.ending(out, "\n")
+		case i+1 == len(rest):
+			lw.pendingCR = true
+		case rest[i+1] == '\n':
+			out = lw.ending(out, "\r\n")
+			i++
+		default:
+			out = lw.ending(out, "\r")
+		}
+		rest = rest[i+1:]
+	}
+	lw.out = out
+	if _, err := lw.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close - count and write a "\r" held back at the end of the input
+func (lw *LineEndingWriter) Close() error {
+	if !lw.pendingCR {
+		return nil
+	}
+	lw.pendingCR = false
+	_, err := lw.w.Write(lw.ending(nil, "\r"))
+	return err
+}
+
+// ending - count a line ending and append it, or its replacement, to out
+func (lw *LineEndingWriter) ending(out []byte, eol string) []byte {
+	switch eol {
+	case "\n":
+		lw.Counts.LF++
+	case "\r\n":
+		lw.Counts.CRLF++
+	default:
+		lw.Counts.CR++
+	}
+	if lw.To != "" {
+		eol = lw.To
+	}
+	return append(out, eol...)
+}
+
+// CountLineEndings - count the line endings of s
+func CountLineEndings(s string) LineEndings {
+	lw := NewLineEndingWriter(io.Discard, "")
+	lw.Write([]byte(s))
+	lw.Close()
+	return lw.Counts
+}
+
+// ConvertLineEndings - replace every line ending of s, whether "\n", "\r\n"
+// or a lone "\r", with eol
+func ConvertLineEndings(s, eol string) string {
+	var buf bytes.Buffer
+	lw := NewLineEndingWriter(&buf, eol)
+	lw.Write([]byte(s))
+	lw.Close()
+	return buf.String()
+}
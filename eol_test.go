@@ -0,0 +1,61 @@
+package changecase
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestLineEndings tests counting and converting line endings
+func TestLineEndings(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		counts LineEndings
+		style  string
+		lf     string
+		crlf   string
+	}{
+		{"empty", "", LineEndings{}, EOLNone, "", ""},
+		{"no endings", "abc", LineEndings{}, EOLNone, "abc", "abc"},
+		{"lf", "a\nb\n", LineEndings{LF: 2}, EOLLF, "a\nb\n", "a\r\nb\r\n"},
+		{"crlf", "a\r\nb\r\n", LineEndings{CRLF: 2}, EOLCRLF, "a\nb\n", "a\r\nb\r\n"},
+		{"cr", "a\rb\r", LineEndings{CR: 2}, EOLCR, "a\nb\n", "a\r\nb\r\n"},
+		{"mixed", "a\r\nb\nc\r", LineEndings{LF: 1, CRLF: 1, CR: 1}, EOLMixed, "a\nb\nc\n", "a\r\nb\r\nc\r\n"},
+		{"cr before crlf", "a\r\r\n", LineEndings{CRLF: 1, CR: 1}, EOLMixed, "a\n\n", "a\r\n\r\n"},
+		{"lf cr", "\n\r", LineEndings{LF: 1, CR: 1}, EOLMixed, "\n\n", "\r\n\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := CountLineEndings(tt.input)
+			if counts != tt.counts || counts.Style() != tt.style {
+				t.Errorf("Expected %+v (%s), got %+v (%s)", tt.counts, tt.style, counts, counts.Style())
+			}
+			if got := ConvertLineEndings(tt.input, "\n"); got != tt.lf {
+				t.Errorf("Expected LF conversion %q, got %q", tt.lf, got)
+			}
+			if got := ConvertLineEndings(tt.input, "\r\n"); got != tt.crlf {
+				t.Errorf("Expected CRLF conversion %q, got %q", tt.crlf, got)
+			}
+
+			// writing one byte at a time splits every "\r\n" across writes
+			var buf bytes.Buffer
+			lw := NewLineEndingWriter(&buf, "")
+			for i := 0; i < len(tt.input); i++ {
+				if _, err := lw.Write([]byte{tt.input[i]}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := lw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.input || lw.Counts != tt.counts {
+				t.Errorf("Byte by byte: expected %q %+v, got %q %+v", tt.input, tt.counts, buf.String(), lw.Counts)
+			}
+		})
+	}
+
+	if _, err := ParseLineEnding("cr"); err == nil {
+		t.Errorf("Expected an error for an unsupported line ending")
+	}
+}
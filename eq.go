@@ -0,0 +1,511 @@
+package changecase
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// MaxExitCode - the largest exit code a process can report; larger values
+// wrap around, so that 256 would read as success
+const MaxExitCode = 255
+
+// ExitCode - cap a count or position reported as an exit code at MaxExitCode
+func ExitCode(n int) int {
+	return min(n, MaxExitCode)
+}
+
+// fuzzyThresholdSlack absorbs floating point error when comparing a score
+// against a threshold, so that 9/10 always satisfies a threshold of 0.9
+const fuzzyThresholdSlack = 1e-9
+
+// ErrArgumentCount - RunEq was given a number of arguments that its mode
+// cannot compare
+var ErrArgumentCount = errors.New("invalid number of arguments")
+
+// EqConfig - how RunEq compares its inputs and what it reports. Exactly one
+// of the modes (Secret, Batch, JSON, Numeric, Diff, Files) may be chosen;
+// without any of them strings are compared rune by rune.
+type EqConfig struct {
+	Options        Options
+	AllDifferences bool      // report every difference, the edit distance and similarity
+	Fuzzy          bool      // succeed when the Algorithm score reaches Threshold
+	Threshold      float64   // the similarity threshold of Fuzzy, from 0 to 1
+	Algorithm      Algorithm // the similarity algorithm of Fuzzy
+	Majority       bool      // compare against the most common string instead of the first
+	AllLines       bool      // read every line of stdin instead of just two
+	Confusables    bool      // explain whether the first difference is a pair of look-alikes
+	Match          MatchMode // match the second string as a pattern against the first
+	Diff           string    // DiffUnified or DiffSideBySide for a line diff
+	Width          int       // the total width of DiffSideBySide output
+	Files          bool      // compare the contents of two files ("-" for stdin)
+	JSON           bool      // compare two JSON documents structurally
+	Unordered      bool      // with JSON, compare arrays as multisets
+	Numeric        bool      // compare numbers within Tolerance, the rest exactly
+	Tolerance      Tolerance // how far apart numbers may be with JSON and Numeric
+	Batch          string    // the manifest of pairs to compare ("-" for stdin)
+	BatchFormat    string    // the format of the Batch manifest
+	Parallel       int       // the number of Batch pairs compared at the same time
+	Secret         bool      // compare two secrets in constant time, reporting nothing
+	Unit           string    // the unit of reported mismatch positions
+	Format         string    // FormatText, FormatJSON or FormatTSV for a two-string comparison
+	Verbose        bool      // describe the comparison in detail
+	Colors         Palette   // the colors of verbose and diff output
+}
+
+// RunEq - compare the inputs named by args, or read from stdin, as configured
+// by cfg, and return the report and the exit code of the eq command: 0 when
+// the inputs match, otherwise the position of the first difference, the
+// number of strings that differ, or 1, capped at MaxExitCode
+func RunEq(cfg EqConfig, args []string, stdin io.Reader) (string, int, error) {
+	switch {
+	case cfg.Secret:
+		code, err := compareSecrets(args, stdin)
+		return "", code, err
+	case cfg.Batch != "":
+		return runEqBatch(cfg, stdin)
+	case cfg.JSON:
+		return runEqJSON(cfg, args, stdin)
+	case cfg.Numeric:
+		return runEqNumeric(cfg, args, stdin)
+	case cfg.Diff != "":
+		return runEqDiff(cfg, args, stdin)
+	case cfg.Files:
+		return runEqFiles(cfg, args, stdin)
+	}
+
+	// Two lines on stdin are compared as the second one is read, unless the
+	// output needs both strings in full
+	if len(args) == 0 && !cfg.AllLines && !cfg.Majority && !cfg.Verbose && !cfg.AllDifferences &&
+		!cfg.Fuzzy && cfg.Match == "" && !cfg.Confusables && !cfg.Options.Remapped() && cfg.Format == FormatText {
+		return runEqStream(cfg, stdin)
+	}
+
+	strs, err := readStrings(args, stdin, cfg.AllLines)
+	if err != nil {
+		return "", 1, err
+	}
+	if len(strs) > 2 || cfg.AllLines || cfg.Majority {
+		return runEqMany(cfg, strs)
+	}
+	return runEqPair(cfg, strs[0], strs[1])
+}
+
+// decodeStdin - return a reader of stdin decoded to UTF-8
+func decodeStdin(stdin io.Reader) (*bufio.Reader, error) {
+	r, _, err := DecodeReader(stdin)
+	if err != nil {
+		return nil, fmt.Errorf("reading stdin: %w", err)
+	}
+	return bufio.NewReader(r), nil
+}
+
+// readStrings - return the strings to compare: args when there are two or
+// more of them, otherwise the first two lines of stdin, or every line of it
+// when readAll is set
+func readStrings(args []string, stdin io.Reader, readAll bool) ([]string, error) {
+	if len(args) >= 2 {
+		return args, nil
+	}
+	if len(args) != 0 {
+		return nil, ErrArgumentCount
+	}
+
+	reader, err := decodeStdin(stdin)
+	if err != nil {
+		return nil, err
+	}
+	str1, ok, err := ReadLine(reader)
+	if !ok || err != nil {
+		return nil, errors.New("reading first line from stdin")
+	}
+	str2, ok, err := ReadLine(reader)
+	if !ok || err != nil {
+		return nil, errors.New("reading second line from stdin")
+	}
+
+	strs := []string{str1, str2}
+	for readAll {
+		str, ok, err := ReadLine(reader)
+		if err != nil {
+			return nil, fmt.Errorf("reading stdin: %w", err)
+		}
+		if !ok {
+			break
+		}
+		strs = append(strs, str)
+	}
+	return strs, nil
+}
+
+// runEqStream - compare the first two lines of stdin while the second one is
+// being read. The first line has to be held in memory until the second one
+// arrives, so memory use grows with its length; only Files is constant.
+func runEqStream(cfg EqConfig, stdin io.Reader) (string, int, error) {
+	reader, err := decodeStdin(stdin)
+	if err != nil {
+		return "", 1, err
+	}
+	str1, ok, err := ReadLine(reader)
+	if !ok || err != nil {
+		return "", 1, errors.New("reading first line from stdin")
+	}
+	if _, err := reader.Peek(1); err != nil {
+		return "", 1, errors.New("reading second line from stdin")
+	}
+
+	position, err := CompareRuneReaders(strings.NewReader(str1), NewLineRuneReader(reader), cfg.Options.CaseInsensitive)
+	if err != nil {
+		return "", 1, fmt.Errorf("reading stdin: %w", err)
+	}
+	if position == 0 {
+		return "0\n", 0, nil
+	}
+	return FormatPosition(Locate(str1, position), cfg.Unit) + "\n", ExitCode(position), nil
+}
+
+// runEqMany - compare every string against a reference string, returning the
+// number of strings that differ as the exit code
+func runEqMany(cfg EqConfig, strs []string) (string, int, error) {
+	if cfg.Format != FormatText {
+		return "", 1, errors.New("-format json and tsv only apply to comparing two strings")
+	}
+	if cfg.AllDifferences || cfg.Fuzzy || cfg.Match != "" {
+		return "", 1, errors.New("-a, -fuzzy and -match work on exactly two strings")
+	}
+
+	var b strings.Builder
+	ref, results := CompareMany(strs, cfg.Majority, cfg.Options)
+	if cfg.Verbose {
+		b.WriteString(cfg.Colors.MaskedRegionsReport(strs, cfg.Options.Masks))
+	}
+	b.WriteString(cfg.Colors.ManyReport(len(strs), ref, results, cfg.Majority, cfg.Verbose, cfg.Unit))
+	return b.String(), ExitCode(CountMismatches(results)), nil
+}
+
+// runEqPair - compare two strings as a pattern match, by similarity, or rune
+// by rune
+func runEqPair(cfg EqConfig, str1, str2 string) (string, int, error) {
+	var b strings.Builder
+	if cfg.Verbose {
+		b.WriteString(cfg.Colors.MaskedRegionsReport([]string{str1, str2}, cfg.Options.Masks))
+	}
+
+	// Match mode looks for the second string as a pattern within the first
+	if cfg.Match != "" {
+		start, end, matched, err := MatchStrings(str1, str2, cfg.Match, cfg.Options)
+		if err != nil {
+			return "", 1, fmt.Errorf("invalid pattern: %w", err)
+		}
+		switch {
+		case cfg.Verbose:
+			b.WriteString(cfg.Colors.MatchReport(str1, cfg.Match, start, end, matched))
+		case matched:
+			b.WriteString("0\n")
+		default:
+			b.WriteString("1\n")
+		}
+		if matched {
+			return b.String(), 0, nil
+		}
+		return b.String(), 1, nil
+	}
+
+	// Fuzzy mode reports a similarity score instead of a mismatch position
+	if cfg.Fuzzy {
+		score, distance := FuzzyScore(str1, str2, cfg.Algorithm, cfg.Options)
+		matched := score >= cfg.Threshold-fuzzyThresholdSlack
+		if cfg.Verbose {
+			b.WriteString(FuzzyReport(cfg.Algorithm, score, distance, cfg.Threshold, matched))
+		} else {
+			fmt.Fprintf(&b, "%.4f\n", score)
+		}
+		if matched {
+			return b.String(), 0, nil
+		}
+		return b.String(), 1, nil
+	}
+
+	res := Compare(str1, str2, cfg.Options)
+	switch {
+	case cfg.Format != FormatText:
+		if err := WriteRecord(&b, cfg.Format, ResultFields(res)); err != nil {
+			return "", 1, err
+		}
+	case cfg.AllDifferences:
+		b.WriteString(AllDifferencesReport(str1, str2, cfg.Options, cfg.Verbose))
+	case cfg.Verbose:
+		b.WriteString(cfg.Colors.VerboseReport(res))
+	case res.Equal():
+		b.WriteString("0\n")
+	default:
+		b.WriteString(FormatPosition(res.Position1, cfg.Unit) + "\n")
+	}
+	if cfg.Confusables {
+		b.WriteString(cfg.Colors.ConfusablesReport(str1, str2, res, cfg.Options.CaseInsensitive))
+	}
+	return b.String(), ExitCode(res.Position1.Rune), nil
+}
+
+// openInput - open a file for reading, or return stdin for "-"
+func openInput(name string, stdin io.Reader) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(stdin), nil
+	}
+	return os.Open(name)
+}
+
+// namedReader prefixes the read errors of a file with its name
+type namedReader struct {
+	name   string
+	reader io.Reader
+}
+
+func (nr namedReader) Read(p []byte) (int, error) {
+	n, err := nr.reader.Read(p)
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("reading %s: %w", nr.name, err)
+	}
+	return n, err
+}
+
+// runEqFiles - stream two files, returning 1 as the exit code if they differ
+func runEqFiles(cfg EqConfig, args []string, stdin io.Reader) (string, int, error) {
+	if len(args) != 2 {
+		return "", 1, ErrArgumentCount
+	}
+	name1, name2 := args[0], args[1]
+	if name1 == "-" && name2 == "-" {
+		return "", 1, errors.New("only one of the files can be stdin")
+	}
+	f1, err := openInput(name1, stdin)
+	if err != nil {
+		return "", 1, fmt.Errorf("opening file: %w", err)
+	}
+	defer f1.Close()
+	f2, err := openInput(name2, stdin)
+	if err != nil {
+		return "", 1, fmt.Errorf("opening file: %w", err)
+	}
+	defer f2.Close()
+
+	r1, _, err := DecodeReader(f1)
+	if err != nil {
+		return "", 1, fmt.Errorf("reading %s: %w", name1, err)
+	}
+	r2, _, err := DecodeReader(f2)
+	if err != nil {
+		return "", 1, fmt.Errorf("reading %s: %w", name2, err)
+	}
+
+	mismatch, err := CompareStreams(namedReader{name1, r1}, namedReader{name2, r2}, cfg.Options.CaseInsensitive)
+	if err != nil {
+		return "", 1, err
+	}
+	report := cfg.Colors.FileReport(name1, name2, mismatch, cfg.Verbose)
+	if mismatch == nil {
+		return report, 0, nil
+	}
+	return report, 1, nil
+}
+
+// readDiffFile - read the whole of a file, where "-" is stdin, decoded to UTF-8
+func readDiffFile(name string, stdin io.Reader) (string, error) {
+	f, err := openInput(name, stdin)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	data, err := ReadAllDecoded(f)
+	return string(data), err
+}
+
+// runEqDiff - diff two multi-line strings, or two files with Files, line by
+// line, returning 1 as the exit code if they differ
+func runEqDiff(cfg EqConfig, args []string, stdin io.Reader) (string, int, error) {
+	label1, label2 := "string 1", "string 2"
+	var text1, text2 string
+	if cfg.Files {
+		if len(args) != 2 {
+			return "", 1, ErrArgumentCount
+		}
+		label1, label2 = args[0], args[1]
+		if label1 == "-" && label2 == "-" {
+			return "", 1, errors.New("only one of the files can be stdin")
+		}
+		var err error
+		if text1, err = readDiffFile(label1, stdin); err != nil {
+			return "", 1, err
+		}
+		if text2, err = readDiffFile(label2, stdin); err != nil {
+			return "", 1, err
+		}
+	} else {
+		// two lines of stdin hold no line breaks to diff, so multi-line
+		// input must come from arguments or files
+		if len(args) != 2 {
+			return "", 1, errors.New("-diff works on two strings, or on two files with -f (one of which may be \"-\" for stdin)")
+		}
+		text1, text2 = args[0], args[1]
+	}
+
+	eol := cfg.Options.Whitespace.NormalizeEOL
+	a := DiffInput{label1, SplitLines(text1, eol)}
+	b := DiffInput{label2, SplitLines(text2, eol)}
+	if !cfg.Files {
+		// strings rarely end in a newline, so only files report a missing one
+		a.NoEOL, b.NoEOL = false, false
+	}
+	report, equal := cfg.Colors.DiffReport(a, b, cfg.Diff, cfg.Options, cfg.Width, cfg.Verbose)
+	if equal {
+		return report, 0, nil
+	}
+	return report, 1, nil
+}
+
+// readJSONDocuments - decode the two documents to compare: the contents of
+// two files with files set, two arguments, or two consecutive documents read
+// from stdin (which may span any number of lines)
+func readJSONDocuments(args []string, files bool, stdin io.Reader) ([2]any, error) {
+	var docs [2]any
+	if len(args) == 0 && !files {
+		decoded, err := DecodeJSONDocuments(stdin, len(docs))
+		if err != nil {
+			return docs, fmt.Errorf("stdin: %w", err)
+		}
+		copy(docs[:], decoded)
+		return docs, nil
+	}
+	if len(args) != 2 {
+		return docs, errors.New("-json needs two documents")
+	}
+
+	for n, arg := range args {
+		data := []byte(arg)
+		if files {
+			var err error
+			if data, err = os.ReadFile(arg); err != nil {
+				return docs, err
+			}
+			data = []byte(DecodeString(string(data)))
+		}
+		doc, err := ParseJSON(data)
+		if err != nil {
+			return docs, fmt.Errorf("document %d: %w", n+1, err)
+		}
+		docs[n] = doc
+	}
+	return docs, nil
+}
+
+// runEqJSON - compare two JSON documents structurally, returning 1 as the
+// exit code if they differ
+func runEqJSON(cfg EqConfig, args []string, stdin io.Reader) (string, int, error) {
+	docs, err := readJSONDocuments(args, cfg.Files, stdin)
+	if err != nil {
+		return "", 1, err
+	}
+
+	d := CompareJSON(docs[0], docs[1], JSONOptions{Tolerance: cfg.Tolerance, UnorderedArrays: cfg.Unordered})
+	var report string
+	switch {
+	case cfg.Verbose:
+		report = cfg.Colors.JSONReport(d)
+	case d == nil:
+		report = "0\n"
+	default:
+		report = d.Path + "\n"
+	}
+	if d != nil {
+		return report, 1, nil
+	}
+	return report, 0, nil
+}
+
+// runEqNumeric - compare two strings token by token, with numbers compared
+// within Tolerance, returning the position in the first string of the first
+// differing token as the exit code
+func runEqNumeric(cfg EqConfig, args []string, stdin io.Reader) (string, int, error) {
+	strs, err := readStrings(args, stdin, false)
+	if err != nil {
+		return "", 1, err
+	}
+	if len(strs) != 2 {
+		return "", 1, errors.New("-numeric works on exactly two strings")
+	}
+	str1, str2 := strs[0], strs[1]
+
+	d := CompareTokens(str1, str2, cfg.Tolerance, cfg.Options.CaseInsensitive)
+	if d == nil {
+		if cfg.Verbose {
+			return cfg.Colors.NumericReport(str1, str2, d, cfg.Tolerance), 0, nil
+		}
+		return "0\n", 0, nil
+	}
+
+	position1, _ := d.Positions(str1, str2)
+	if cfg.Verbose {
+		return cfg.Colors.NumericReport(str1, str2, d, cfg.Tolerance), ExitCode(position1), nil
+	}
+	return FormatPosition(Locate(str1, position1), cfg.Unit) + "\n", ExitCode(position1), nil
+}
+
+// runEqBatch - compare every pair of a manifest, returning 1 as the exit code
+// if any of them differ
+func runEqBatch(cfg EqConfig, stdin io.Reader) (string, int, error) {
+	input := stdin
+	if cfg.Batch != "-" {
+		file, err := os.Open(cfg.Batch)
+		if err != nil {
+			return "", 1, err
+		}
+		defer file.Close()
+		input = file
+	}
+	pairs, err := ReadManifest(input, cfg.Batch, cfg.BatchFormat)
+	if err != nil {
+		return "", 1, fmt.Errorf("%s: %w", cfg.Batch, err)
+	}
+
+	results := CompareBatch(pairs, cfg.Options, cfg.Parallel)
+	report := cfg.Colors.BatchReport(pairs, results, cfg.Unit, cfg.Verbose)
+	if CountMismatches(results) > 0 {
+		return report, 1, nil
+	}
+	return report, 0, nil
+}
+
+// compareSecrets - compare two secrets read from sources (two lines of stdin
+// when there are none) and return the exit code. Errors only name the
+// failing source, never anything about the secrets.
+func compareSecrets(sources []string, stdin io.Reader) (int, error) {
+	switch len(sources) {
+	case 0:
+		sources = []string{"-", "-"}
+	case 2:
+	default:
+		return 1, errors.New("-secret compares exactly two sources")
+	}
+
+	reader := bufio.NewReader(stdin)
+	secrets := make([][]byte, len(sources))
+	for i, source := range sources {
+		secret, err := ReadSecret(source, reader)
+		if err != nil {
+			return 1, fmt.Errorf("reading secret %d: %w", i+1, err)
+		}
+		secrets[i] = secret
+	}
+
+	equal := SecretsEqual(secrets[0], secrets[1])
+	for _, secret := range secrets {
+		clear(secret)
+	}
+	if equal {
+		return 0, nil
+	}
+	return 1, nil
+}
@@ -0,0 +1,117 @@
+package changecase
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRunEq tests the report and exit code of each mode of RunEq
+func TestRunEq(t *testing.T) {
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "a.txt")
+	file2 := filepath.Join(dir, "b.txt")
+	if err := os.WriteFile(file1, []byte("one\ntwo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file2, []byte("one\nTwo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runes := EqConfig{Unit: UnitRune, Format: FormatText}
+
+	tests := []struct {
+		name     string
+		cfg      func(cfg *EqConfig)
+		args     []string
+		stdin    string
+		expected string
+		code     int
+	}{
+		{"equal", nil, []string{"abc", "abc"}, "", "0\n", 0},
+		{"differ", nil, []string{"abc", "abd"}, "", "3\n", 3},
+		{"byte unit", func(cfg *EqConfig) { cfg.Unit = UnitByte }, []string{"\u00e9a", "\u00e9b"}, "", "3\n", 2},
+		{"exit code cap", nil, []string{strings.Repeat("a", 300) + "x", strings.Repeat("a", 300) + "y"}, "", "301\n", MaxExitCode},
+		{"streamed from stdin", nil, nil, "abc\nabd\n", "3\n", 3},
+		{"lines of stdin", nil, nil, "abc\nabc\n", "0\n", 0},
+		{"many", nil, []string{"a", "a", "b", "c"}, "", "string 3 differs at position 1\nstring 4 differs at position 1\n", 2},
+		{"every line", func(cfg *EqConfig) { cfg.AllLines = true }, nil, "a\na\nb\n", "string 3 differs at position 1\n", 1},
+		{"match", func(cfg *EqConfig) { cfg.Match = MatchContains }, []string{"hello", "ell"}, "", "0\n", 0},
+		{"no match", func(cfg *EqConfig) { cfg.Match = MatchPrefix }, []string{"hello", "ell"}, "", "1\n", 1},
+		{"fuzzy", func(cfg *EqConfig) { cfg.Fuzzy, cfg.Threshold, cfg.Algorithm = true, 0.5, AlgoLevenshtein }, []string{"abcd", "abce"}, "", "0.7500\n", 0},
+		{"all differences", func(cfg *EqConfig) { cfg.AllDifferences = true }, []string{"abc", "axc"}, "", "2c2\n< \"b\"\n> \"x\"\nEdit distance: 2\nSimilarity: 66.67%\n", 2},
+		{"files", func(cfg *EqConfig) { cfg.Files = true }, []string{file1, file2}, "", "line 2, column 1, byte offset 4\n", 1},
+		{"files case-insensitive", func(cfg *EqConfig) { cfg.Files, cfg.Options.CaseInsensitive = true, true }, []string{file1, file2}, "", "0\n", 0},
+		{"file from stdin", func(cfg *EqConfig) { cfg.Files = true }, []string{file1, "-"}, "one\ntwo\n", "0\n", 0},
+		{"diff", func(cfg *EqConfig) { cfg.Diff = DiffUnified }, []string{"a\nb", "a\nc"}, "", "--- string 1\n+++ string 2\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n", 1},
+		{"diff of files", func(cfg *EqConfig) { cfg.Diff, cfg.Files = DiffUnified, true }, []string{file1, file1}, "", "", 0},
+		{"json", func(cfg *EqConfig) { cfg.JSON = true }, []string{`{"a": [1, 2]}`, `{"a": [1, 3]}`}, "", ".a[1]\n", 1},
+		{"json from stdin", func(cfg *EqConfig) { cfg.JSON = true }, nil, "{\"a\":\n1} {\"a\": 1}", "0\n", 0},
+		{"numeric", func(cfg *EqConfig) { cfg.Numeric = true }, []string{"x 1.0 y", "x 1 z"}, "", "7\n", 7},
+		{"numeric tolerance", func(cfg *EqConfig) { cfg.Numeric, cfg.Tolerance.Absolute = true, 0.1 }, []string{"x 1.0", "x 1.05"}, "", "0\n", 0},
+		{"batch", func(cfg *EqConfig) { cfg.Batch, cfg.BatchFormat, cfg.Parallel = "-", ManifestTSV, 1 }, nil, "a\ta\nb\tc\n",
+			"#  RESULT  POSITION  NAME\n1  ok      -         line 1\n2  FAIL    1         line 2\nSummary: 2 pair(s), 1 passed, 1 failed\n", 1},
+		{"secret", func(cfg *EqConfig) { cfg.Secret = true }, nil, "s3cret\ns3cret\n", "", 0},
+		{"secret differs", func(cfg *EqConfig) { cfg.Secret = true }, nil, "s3cret\nother\n", "", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := runes
+			if tt.cfg != nil {
+				tt.cfg(&cfg)
+			}
+			output, code, err := RunEq(cfg, tt.args, strings.NewReader(tt.stdin))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if output != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, output)
+			}
+			if code != tt.code {
+				t.Errorf("Expected exit code %d, got %d", tt.code, code)
+			}
+		})
+	}
+}
+
+// TestRunEqErrors tests the inputs that RunEq cannot compare
+func TestRunEqErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      EqConfig
+		args     []string
+		stdin    string
+		expected string
+	}{
+		{"one argument", EqConfig{}, []string{"a"}, "", ErrArgumentCount.Error()},
+		{"one line of stdin", EqConfig{}, nil, "a\n", "reading second line from stdin"},
+		{"empty stdin", EqConfig{Verbose: true}, nil, "", "reading first line from stdin"},
+		{"many with -a", EqConfig{AllDifferences: true, Format: FormatText}, []string{"a", "b", "c"}, "", "-a, -fuzzy and -match work on exactly two strings"},
+		{"invalid pattern", EqConfig{Match: MatchRegex}, []string{"a", "("}, "", "invalid pattern: "},
+		{"both files on stdin", EqConfig{Files: true}, []string{"-", "-"}, "", "only one of the files can be stdin"},
+		{"missing file", EqConfig{Files: true}, []string{"missing.txt", "-"}, "", "missing.txt"},
+		{"diff of stdin", EqConfig{Diff: DiffUnified}, nil, "a\nb\n", "-diff works on two strings"},
+		{"one json document", EqConfig{JSON: true}, []string{"1"}, "", "-json needs two documents"},
+		{"three numeric strings", EqConfig{Numeric: true}, []string{"1", "2", "3"}, "", "-numeric works on exactly two strings"},
+		{"odd batch", EqConfig{Batch: "-", BatchFormat: ManifestPairs}, nil, "a\n", "-: odd number of lines (1)"},
+		{"one secret", EqConfig{Secret: true}, []string{"env:A"}, "", "-secret compares exactly two sources"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, code, err := RunEq(tt.cfg, tt.args, strings.NewReader(tt.stdin))
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected an error containing %q, got %v", tt.expected, err)
+			}
+			if code != 1 {
+				t.Errorf("Expected exit code 1, got %d", code)
+			}
+		})
+	}
+
+	if _, _, err := RunEq(EqConfig{}, []string{"a"}, strings.NewReader("")); !errors.Is(err, ErrArgumentCount) {
+		t.Errorf("Expected ErrArgumentCount, got %v", err)
+	}
+}
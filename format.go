@@ -0,0 +1,207 @@
+package changecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Output formats accepted by the -format option of every command
+const (
+	FormatText = "text" // the command's usual human-readable output
+	FormatJSON = "json" // a single JSON object on one line
+	FormatTSV  = "tsv"  // a header row of field names and a row of values
+)
+
+// Field - a named value of a structured record. A Value of type []Field is
+// a nested record: an object in JSON, or "name.child" columns in TSV.
+type Field struct {
+	Name  string
+	Value any
+}
+
+// ParseFormat - validate the name of an output format
+func ParseFormat(name string) (string, error) {
+	switch name {
+	case FormatText, FormatJSON, FormatTSV:
+		return name, nil
+	}
+	return "", fmt.Errorf("unknown format %q (use json, text or tsv)", name)
+}
+
+// CommandOptions - the options shared by the commands that otherwise treat
+// every argument as input
+type CommandOptions struct {
+	Format string // FormatText, FormatJSON or FormatTSV
+	BOM    bool   // start text output with a byte order mark
+}
+
+// ParseCommandArgs - remove leading -format and -bom options from the
+// arguments of a command that otherwise treats every argument as input, so
+// "-format json", "--format json", "-format=json" and "--bom" are all
+// accepted. A "--" argument ends the options. Without -format the format is
+// FormatText.
+func ParseCommandArgs(args []string) (CommandOptions, []string, error) {
+	opts := CommandOptions{Format: FormatText}
+	for len(args) > 0 {
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(args[0], "-"), "=")
+		if args[0] == "--" {
+			return opts, args[1:], nil
+		}
+		name = strings.TrimPrefix(name, "-")
+		if name == "bom" && !hasValue && strings.HasPrefix(args[0], "-") {
+			opts.BOM = true
+			args = args[1:]
+			continue
+		}
+		if name != "format" || !strings.HasPrefix(args[0], "-") {
+			break
+		}
+		args = args[1:]
+		if !hasValue {
+			if len(args) == 0 {
+				return CommandOptions{}, nil, fmt.Errorf("-format needs a value (json, text or tsv)")
+			}
+			value, args = args[0], args[1:]
+		}
+		var err error
+		if opts.Format, err = ParseFormat(value); err != nil {
+			return CommandOptions{}, nil, err
+		}
+	}
+	if opts.BOM && opts.Format != FormatText {
+		return CommandOptions{}, nil, fmt.Errorf("-bom only applies to text output")
+	}
+	return opts, args, nil
+}
+
+// WriteRecord - write fields as a single JSON object on one line, or as a TSV
+// header row and value row. Fields are written in the order given so that
+// the output is stable.
+func WriteRecord(w io.Writer, format string, fields []Field) error {
+	return WriteRecords(w, format, [][]Field{fields})
+}
+
+// WriteRecords - write several records with the same fields, as one JSON
+// object per line, or as a TSV header row followed by a row per record
+func WriteRecords(w io.Writer, format string, records [][]Field) error {
+	var buf bytes.Buffer
+	for i, fields := range records {
+		switch format {
+		case FormatJSON:
+			if err := writeJSONRecord(&buf, fields); err != nil {
+				return err
+			}
+			buf.WriteByte('\n')
+		case FormatTSV:
+			var names, values []string
+			flattenRecord("", fields, &names, &values)
+			if i == 0 {
+				buf.WriteString(strings.Join(names, "\t") + "\n")
+			}
+			buf.WriteString(strings.Join(values, "\t") + "\n")
+		default:
+			return fmt.Errorf("format %q has no structured output", format)
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeJSONRecord - write fields as a JSON object, keeping their order
+func writeJSONRecord(buf *bytes.Buffer, fields []Field) error {
+	buf.WriteByte('{')
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := writeJSONValue(buf, field.Name); err != nil {
+			return err
+		}
+		buf.WriteByte(':')
+		if nested, ok := field.Value.([]Field); ok {
+			if err := writeJSONRecord(buf, nested); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := writeJSONValue(buf, field.Value); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// writeJSONValue - write a single value without escaping <, > and &, which
+// would only make text such as mask placeholders harder to read
+func writeJSONValue(buf *bytes.Buffer, value any) error {
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return err
+	}
+	buf.Write(bytes.TrimSuffix(out.Bytes(), []byte("\n")))
+	return nil
+}
+
+// flattenRecord - collect the TSV column names and values of fields, naming
+// the columns of nested records "parent.child"
+func flattenRecord(prefix string, fields []Field, names, values *[]string) {
+	for _, field := range fields {
+		if nested, ok := field.Value.([]Field); ok {
+			flattenRecord(prefix+field.Name+".", nested, names, values)
+			continue
+		}
+		*names = append(*names, prefix+field.Name)
+		*values = append(*values, EscapeTSV(fmt.Sprint(field.Value)))
+	}
+}
+
+// EscapeTSV - escape backslashes, tabs and line breaks so that s fits in a
+// single TSV cell
+func EscapeTSV(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`).Replace(s)
+}
+
+// PositionFields - the fields of a position in every supported unit; the
+// zero position of a match has a zero offset too
+func PositionFields(pos Position) []Field {
+	offset := 0
+	if pos.Byte > 0 {
+		offset = pos.Offset()
+	}
+	return []Field{
+		{"rune", pos.Rune},
+		{"byte", pos.Byte},
+		{"offset", offset},
+		{"column", pos.Column},
+		{"line", pos.Line},
+		{"line_column", pos.LineColumn},
+	}
+}
+
+// SnippetFields - the fields of the context around a mismatch
+func SnippetFields(snippet Snippet) []Field {
+	return []Field{
+		{"before", snippet.Before},
+		{"at", snippet.At},
+		{"after", snippet.After},
+	}
+}
+
+// ResultFields - the fields of a comparison result. The positions are all
+// zero when the strings match, so the set of fields never changes.
+func ResultFields(res Result) []Field {
+	return []Field{
+		{"match", res.Equal()},
+		{"kind", res.Kind.String()},
+		{"position1", PositionFields(res.Position1)},
+		{"position2", PositionFields(res.Position2)},
+		{"context1", SnippetFields(res.Context1)},
+		{"context2", SnippetFields(res.Context2)},
+	}
+}
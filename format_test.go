@@ -0,0 +1,82 @@
+package changecase
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// TestWriteRecord tests the JSON and TSV rendering of nested records
+func TestWriteRecord(t *testing.T) {
+	fields := []Field{
+		{"input", "a<b\tc"},
+		{"count", 3},
+		{"nested", []Field{{"ok", true}, {"text", `x\y`}}},
+	}
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{FormatJSON, `{"input":"a<b\tc","count":3,"nested":{"ok":true,"text":"x\\y"}}` + "\n"},
+		{FormatTSV, "input\tcount\tnested.ok\tnested.text\na<b\\tc\t3\ttrue\tx\\\\y\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteRecord(&buf, tt.format, fields); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, buf.String())
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	records := [][]Field{{{"n", 1}, {"s", "a"}}, {{"n", 2}, {"s", "b"}}}
+	if err := WriteRecords(&buf, FormatTSV, records); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "n\ts\n1\ta\n2\tb\n"; buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	if err := WriteRecord(&bytes.Buffer{}, FormatText, fields); err == nil {
+		t.Errorf("Expected an error for text format")
+	}
+}
+
+// TestParseCommandArgs tests that leading -format and -bom options are removed
+func TestParseCommandArgs(t *testing.T) {
+	tests := []struct {
+		args     []string
+		opts     CommandOptions
+		rest     []string
+		hasError bool
+	}{
+		{[]string{"hello", "world"}, CommandOptions{Format: FormatText}, []string{"hello", "world"}, false},
+		{[]string{"-format", "json", "hello"}, CommandOptions{Format: FormatJSON}, []string{"hello"}, false},
+		{[]string{"--format=tsv", "hello"}, CommandOptions{Format: FormatTSV}, []string{"hello"}, false},
+		{[]string{"-bom", "hello"}, CommandOptions{Format: FormatText, BOM: true}, []string{"hello"}, false},
+		{[]string{"--bom", "-format", "text", "x"}, CommandOptions{Format: FormatText, BOM: true}, []string{"x"}, false},
+		{[]string{"bom", "format"}, CommandOptions{Format: FormatText}, []string{"bom", "format"}, false},
+		{[]string{"hello", "-format", "json"}, CommandOptions{Format: FormatText}, []string{"hello", "-format", "json"}, false},
+		{[]string{"--", "-format", "json"}, CommandOptions{Format: FormatText}, []string{"-format", "json"}, false},
+		{[]string{"-x"}, CommandOptions{Format: FormatText}, []string{"-x"}, false},
+		{[]string{"-format", "xml", "hello"}, CommandOptions{}, nil, true},
+		{[]string{"-format"}, CommandOptions{}, nil, true},
+		{[]string{"-bom", "-format", "json", "x"}, CommandOptions{}, nil, true},
+	}
+
+	for _, tt := range tests {
+		opts, rest, err := ParseCommandArgs(tt.args)
+		if (err != nil) != tt.hasError {
+			t.Errorf("%q: unexpected error %v", tt.args, err)
+			continue
+		}
+		if opts != tt.opts || !reflect.DeepEqual(rest, tt.rest) {
+			t.Errorf("%q: expected %+v %q, got %+v %q", tt.args, tt.opts, tt.rest, opts, rest)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package changecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+)
+
+// JSONOptions - how two JSON documents are compared by CompareJSON
+type JSONOptions struct {
+	Tolerance       Tolerance // how far apart numbers may be and still be equal
+	UnorderedArrays bool      // arrays are equal when they hold the same elements in any order
+}
+
+// JSONDifference - the first difference found between two JSON documents
+type JSONDifference struct {
+	Path    string // jq-style path such as ".items[3].name", or "." for the root
+	Reason  string // what differs, e.g. "values differ" or "only in first document"
+	Value1  any    // the value in the first document, or nil when it is missing
+	Value2  any    // the value in the second document, or nil when it is missing
+	Missing int    // 1 or 2 when the value has no counterpart in that document, else 0
+}
+
+// ParseJSON - decode a single JSON document, keeping numbers as json.Number
+// so that they are compared exactly. Trailing data after the document is an
+// error.
+func ParseJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	value, err := DecodeJSON(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the JSON document")
+	}
+	return value, nil
+}
+
+// DecodeJSON - decode the next JSON document from dec, keeping numbers as
+// json.Number so that they are compared exactly
+func DecodeJSON(dec *json.Decoder) (any, error) {
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// DecodeJSONDocuments - decode count consecutive JSON documents from r,
+// decoded as by DecodeReader, each of which may span any number of lines
+func DecodeJSONDocuments(r io.Reader, count int) ([]any, error) {
+	decoded, _, err := DecodeReader(r)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(decoded)
+	docs := make([]any, count)
+	for n := range docs {
+		if docs[n], err = DecodeJSON(dec); err != nil {
+			return nil, fmt.Errorf("document %d: %w", n+1, err)
+		}
+	}
+	return docs, nil
+}
+
+// CompareJSON - compare two decoded JSON documents structurally, ignoring
+// object key order, and return the first difference or nil when they are
+// equal. Object keys are visited in sorted order so the result is stable.
+func CompareJSON(a, b any, opts JSONOptions) *JSONDifference {
+	return compareJSONAt("", a, b, opts)
+}
+
+// jsonIdentifier - object keys that can be written as ".key" in a path
+var jsonIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// JSONPathKey - append an object key to a path, quoting it as ."key" when
+// it is not a plain identifier
+func JSONPathKey(path, key string) string {
+	if jsonIdentifier.MatchString(key) {
+		return path + "." + key
+	}
+	quoted, _ := json.Marshal(key)
+	return path + "." + string(quoted)
+}
+
+// JSONPathIndex - append an array index to a path
+func JSONPathIndex(path string, index int) string {
+	return fmt.Sprintf("%s[%d]", path, index)
+}
+
+// compareJSONAt - compare a and b, which are found at path in each document
+func compareJSONAt(path string, a, b any, opts JSONOptions) *JSONDifference {
+	diff := func(reason string) *JSONDifference {
+		if path == "" {
+			path = "."
+		}
+		return &JSONDifference{Path: path, Reason: reason, Value1: a, Value2: b}
+	}
+
+	if type1, type2 := JSONType(a), JSONType(b); type1 != type2 {
+		return diff(fmt.Sprintf("types differ (%s vs %s)", type1, type2))
+	}
+
+	switch v1 := a.(type) {
+	case map[string]any:
+		v2 := b.(map[string]any)
+		keys := make([]string, 0, len(v1)+len(v2))
+		for key := range v1 {
+			keys = append(keys, key)
+		}
+		for key := range v2 {
+			if _, ok := v1[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child1, ok1 := v1[key]
+			child2, ok2 := v2[key]
+			switch {
+			case !ok2:
+				return &JSONDifference{Path: JSONPathKey(path, key), Reason: "only in first document", Value1: child1, Missing: 2}
+			case !ok1:
+				return &JSONDifference{Path: JSONPathKey(path, key), Reason: "only in second document", Value2: child2, Missing: 1}
+			}
+			if d := compareJSONAt(JSONPathKey(path, key), child1, child2, opts); d != nil {
+				return d
+			}
+		}
+	case []any:
+		v2 := b.([]any)
+		if opts.UnorderedArrays {
+			return compareUnordered(path, v1, v2, opts)
+		}
+		for i := 0; i < len(v1) && i < len(v2); i++ {
+			if d := compareJSONAt(JSONPathIndex(path, i), v1[i], v2[i], opts); d != nil {
+				return d
+			}
+		}
+		if len(v1) > len(v2) {
+			return &JSONDifference{Path: JSONPathIndex(path, len(v2)), Reason: "only in first document", Value1: v1[len(v2)], Missing: 2}
+		}
+		if len(v2) > len(v1) {
+			return &JSONDifference{Path: JSONPathIndex(path, len(v1)), Reason: "only in second document", Value2: v2[len(v1)], Missing: 1}
+		}
+	case json.Number:
+		if !numbersEqual(string(v1), string(b.(json.Number)), opts.Tolerance) {
+			return diff("values differ")
+		}
+	default:
+		if a != b {
+			return diff("values differ")
+		}
+	}
+	return nil
+}
+
+// compareUnordered - pair every element of a with a distinct equal element
+// of b, reporting the first element of either array left without a partner.
+// With a tolerance, equality is not transitive, so the pairing is a maximum
+// bipartite matching found with augmenting paths rather than a greedy one.
+func compareUnordered(path string, a, b []any, opts JSONOptions) *JSONDifference {
+	// equal[i] lists the elements of b that a[i] may be paired with
+	equal := make([][]int, len(a))
+	for i := range a {
+		for j := range b {
+			if compareJSONAt("", a[i], b[j], opts) == nil {
+				equal[i] = append(equal[i], j)
+			}
+		}
+	}
+
+	partner1 := make([]int, len(a))
+	partner2 := make([]int, len(b))
+	for i := range partner1 {
+		partner1[i] = -1
+	}
+	for j := range partner2 {
+		partner2[j] = -1
+	}
+
+	// augment tries to pair a[i], moving earlier pairs to other partners
+	// where that frees up an element of b
+	var augment func(i int, visited []bool) bool
+	augment = func(i int, visited []bool) bool {
+		for _, j := range equal[i] {
+			if visited[j] {
+				continue
+			}
+			visited[j] = true
+			if partner2[j] < 0 || augment(partner2[j], visited) {
+				partner1[i], partner2[j] = j, i
+				return true
+			}
+		}
+		return false
+	}
+	for i := range a {
+		augment(i, make([]bool, len(b)))
+	}
+
+	for i, elem := range a {
+		if partner1[i] < 0 {
+			return &JSONDifference{Path: JSONPathIndex(path, i), Reason: "no equal element in second document", Value1: elem, Missing: 2}
+		}
+	}
+	for j, elem := range b {
+		if partner2[j] < 0 {
+			return &JSONDifference{Path: JSONPathIndex(path, j), Reason: "no equal element in first document", Value2: elem, Missing: 1}
+		}
+	}
+	return nil
+}
+
+// JSONType - return the JSON type name of a decoded value
+func JSONType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,92 @@
+package changecase
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestCompareJSON tests the structural comparison and the reported path
+func TestCompareJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		doc1   string
+		doc2   string
+		opts   JSONOptions
+		path   string
+		reason string
+	}{
+		{"key order and whitespace", `{"a":1,"b":[true,null]}`, "{ \"b\": [ true, null ],\n  \"a\": 1 }", JSONOptions{}, "", ""},
+		{"root scalar", `"x"`, `"y"`, JSONOptions{}, ".", "values differ"},
+		{"nested value", `{"items":[{},{},{},{"name":"a"}]}`, `{"items":[{},{},{},{"name":"b"}]}`, JSONOptions{}, ".items[3].name", "values differ"},
+		{"type", `{"n":1}`, `{"n":"1"}`, JSONOptions{}, ".n", "types differ (number vs string)"},
+		{"missing key", `{"a":1,"b":2}`, `{"a":1}`, JSONOptions{}, ".b", "only in first document"},
+		{"extra key", `{"a":1}`, `{"a":1,"c":2}`, JSONOptions{}, ".c", "only in second document"},
+		{"quoted key", `{"a b":1}`, `{"a b":2}`, JSONOptions{}, `."a b"`, "values differ"},
+		{"longer array", `[1,2,3]`, `[1,2]`, JSONOptions{}, "[2]", "only in first document"},
+		{"equal numbers", `[1, 1.0, 1e0, 100000000000000000001]`, `[1.00, 1, 10e-1, 100000000000000000001]`, JSONOptions{}, "", ""},
+		{"big numbers", `100000000000000000001`, `100000000000000000000`, JSONOptions{}, ".", "values differ"},
+		{"within tolerance", `{"x":1.0000001}`, `{"x":1.0}`, JSONOptions{Tolerance: Tolerance{Absolute: 1e-6}}, "", ""},
+		{"outside tolerance", `{"x":1.001}`, `{"x":1.0}`, JSONOptions{Tolerance: Tolerance{Absolute: 1e-6}}, ".x", "values differ"},
+		{"array order", `[1,2,3]`, `[3,1,2]`, JSONOptions{}, "[0]", "values differ"},
+		{"relative tolerance", `{"x":1000001}`, `{"x":1000000}`, JSONOptions{Tolerance: Tolerance{Relative: 1e-5}}, "", ""},
+		{"unordered", `[1,{"a":2},3]`, `[3,1,{"a":2}]`, JSONOptions{UnorderedArrays: true}, "", ""},
+		{"unordered duplicates", `[1,1,2]`, `[1,2,2]`, JSONOptions{UnorderedArrays: true}, "[1]", "no equal element in second document"},
+		{"unordered extra", `[1]`, `[1,2]`, JSONOptions{UnorderedArrays: true}, "[1]", "no equal element in first document"},
+		{"unordered within tolerance", `[1.0,1.05]`, `[1.04,0.96]`, JSONOptions{Tolerance: Tolerance{Absolute: 0.05}, UnorderedArrays: true}, "", ""},
+		{"unordered tolerance unmatched", `[1.0,1.05,1.2]`, `[1.04,0.96,1.0]`, JSONOptions{Tolerance: Tolerance{Absolute: 0.05}, UnorderedArrays: true}, "[2]", "no equal element in second document"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc1, err := ParseJSON([]byte(tt.doc1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc2, err := ParseJSON([]byte(tt.doc2))
+			if err != nil {
+				t.Fatal(err)
+			}
+			d := CompareJSON(doc1, doc2, tt.opts)
+			if tt.path == "" {
+				if d != nil {
+					t.Errorf("Expected no difference, got %+v", d)
+				}
+				return
+			}
+			if d == nil {
+				t.Fatalf("Expected a difference at %s, got none", tt.path)
+			}
+			if d.Path != tt.path || d.Reason != tt.reason {
+				t.Errorf("Expected %s (%s), got %s (%s)", tt.path, tt.reason, d.Path, d.Reason)
+			}
+		})
+	}
+}
+
+// TestParseJSON tests that invalid and trailing data are rejected
+func TestParseJSON(t *testing.T) {
+	for _, doc := range []string{`{"a":`, `{} {}`, ``, `nul`} {
+		if _, err := ParseJSON([]byte(doc)); err == nil {
+			t.Errorf("Expected an error for %q", doc)
+		}
+	}
+}
+
+// TestDecodeJSONDocuments tests reading consecutive documents from a stream
+func TestDecodeJSONDocuments(t *testing.T) {
+	docs, err := DecodeJSONDocuments(strings.NewReader("\ufeff{\"a\":\n 1}\n[2] \"extra\""), 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if d := CompareJSON(docs[0], map[string]any{"a": json.Number("1")}, JSONOptions{}); d != nil {
+		t.Errorf("Unexpected first document %v", docs[0])
+	}
+	if d := CompareJSON(docs[1], []any{json.Number("2")}, JSONOptions{}); d != nil {
+		t.Errorf("Unexpected second document %v", docs[1])
+	}
+
+	if _, err := DecodeJSONDocuments(strings.NewReader("{} {"), 2); err == nil || !strings.HasPrefix(err.Error(), "document 2: ") {
+		t.Errorf("Expected an error for document 2, got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package changecase
+
+import "strings"
+
+// LineText - the lines of a text to compare with CompareLines
+type LineText struct {
+	Lines []string
+	NoEOL bool // the last line has no trailing newline
+}
+
+// SplitLines - split text into lines; with eol set, CRLF and a lone CR also
+// end a line
+func SplitLines(text string, eol bool) LineText {
+	var lt LineText
+	if text == "" {
+		return lt
+	}
+	if eol {
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+		text = strings.ReplaceAll(text, "\r", "\n")
+	}
+	lt.NoEOL = !strings.HasSuffix(text, "\n")
+	lt.Lines = strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	return lt
+}
+
+// LineOp - a single line of a line diff
+type LineOp struct {
+	Kind  byte // ' ' for an unchanged line, '-' for a removed one, '+' for an added one
+	Line1 int  // index of the line in the first text (or of the next line, for '+')
+	Line2 int  // index of the line in the second text (or of the next line, for '-')
+}
+
+// CompareLines - diff two texts line by line, honoring the case, whitespace
+// and skeleton options, and return one LineOp per line, with the removed
+// lines of each change before the added ones. As with diff, a missing final
+// newline makes the last line differ.
+func CompareLines(a, b LineText, opts Options) []LineOp {
+	return lineOps(DiffLines(lineKeys(a, opts), lineKeys(b, opts)))
+}
+
+// lineKeys - return the value each line is compared by
+func lineKeys(lt LineText, opts Options) []string {
+	keys := make([]string, len(lt.Lines))
+	for i, line := range lt.Lines {
+		runes, _ := Prepare(line, opts)
+		keys[i] = string(runes)
+		if lt.NoEOL && i == len(lt.Lines)-1 {
+			keys[i] += "\x00"
+		}
+	}
+	return keys
+}
+
+// lineOps - expand an edit script into one LineOp per line
+func lineOps(script []DiffRange) []LineOp {
+	var ops, removed, added []LineOp
+	flush := func() {
+		ops = append(ops, removed...)
+		ops = append(ops, added...)
+		removed, added = nil, nil
+	}
+	for _, r := range script {
+		switch r.Op {
+		case DiffEqual:
+			flush()
+			for k := 0; k < r.End1-r.Start1; k++ {
+				ops = append(ops, LineOp{' ', r.Start1 + k, r.Start2 + k})
+			}
+		case DiffDelete:
+			for i := r.Start1; i < r.End1; i++ {
+				removed = append(removed, LineOp{'-', i, r.Start2})
+			}
+		case DiffInsert:
+			for j := r.Start2; j < r.End2; j++ {
+				added = append(added, LineOp{'+', r.Start1, j})
+			}
+		}
+	}
+	flush()
+	return ops
+}
+
+// CountLineChanges - return the number of removed and added lines
+func CountLineChanges(ops []LineOp) (int, int) {
+	removed, added := 0, 0
+	for _, op := range ops {
+		switch op.Kind {
+		case '-':
+			removed++
+		case '+':
+			added++
+		}
+	}
+	return removed, added
+}
+
+// ChangeBlock - return the end of the run of removed and added lines
+// starting at ops[start], and the index of its first added line
+func ChangeBlock(ops []LineOp, start int) (int, int) {
+	mid := start
+	for mid < len(ops) && ops[mid].Kind == '-' {
+		mid++
+	}
+	end := mid
+	for end < len(ops) && ops[end].Kind == '+' {
+		end++
+	}
+	return end, mid
+}
+
+// Hunk - a group of line ops shown together in a unified diff
+type Hunk struct {
+	Start  int // index of the first op of the hunk
+	End    int // index after the last op of the hunk
+	Start1 int // 1-based first line in the first text, or the line before an empty hunk
+	Count1 int // number of lines from the first text
+	Start2 int // 1-based first line in the second text, or the line before an empty hunk
+	Count2 int // number of lines from the second text
+}
+
+// GroupHunks - return the hunks of a unified diff: every change plus up to
+// context unchanged lines either side, merging changes at most 2*context
+// unchanged lines apart as diff does
+func GroupHunks(ops []LineOp, context int) []Hunk {
+	var hunks []Hunk
+	for k := 0; k < len(ops); k++ {
+		if ops[k].Kind == ' ' {
+			continue
+		}
+		last := k
+		for m := k + 1; m < len(ops) && m-last <= 2*context+1; m++ {
+			if ops[m].Kind != ' ' {
+				last = m
+			}
+		}
+
+		hunk := Hunk{Start: max(0, k-context), End: min(len(ops), last+context+1)}
+		hunk.Start1, hunk.Start2 = ops[hunk.Start].Line1, ops[hunk.Start].Line2
+		for _, op := range ops[hunk.Start:hunk.End] {
+			if op.Kind != '+' {
+				hunk.Count1++
+			}
+			if op.Kind != '-' {
+				hunk.Count2++
+			}
+		}
+		if hunk.Count1 > 0 {
+			hunk.Start1++
+		}
+		if hunk.Count2 > 0 {
+			hunk.Start2++
+		}
+		hunks = append(hunks, hunk)
+		k = last
+	}
+	return hunks
+}
+
+// Segment - a run of runes within a line that is either changed or not
+type Segment struct {
+	Runes   []rune
+	Changed bool
+}
+
+// IntraLineSegments - split a removed line and the added line paired with it
+// into changed and unchanged runs, using the rune diff of DiffRunes
+func IntraLineSegments(old, new string) ([]Segment, []Segment) {
+	runes1, runes2 := []rune(old), []rune(new)
+	var segs1, segs2 []Segment
+	for _, r := range DiffRunes(runes1, runes2) {
+		changed := r.Op != DiffEqual
+		if r.End1 > r.Start1 {
+			segs1 = append(segs1, Segment{runes1[r.Start1:r.End1], changed})
+		}
+		if r.End2 > r.Start2 {
+			segs2 = append(segs2, Segment{runes2[r.Start2:r.End2], changed})
+		}
+	}
+	return segs1, segs2
+}
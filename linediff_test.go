@@ -0,0 +1,127 @@
+package changecase
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestSplitLines tests splitting text into lines with and without -eol
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		eol      bool
+		expected LineText
+	}{
+		{"empty", "", false, LineText{}},
+		{"trailing newline", "a\nb\n", false, LineText{[]string{"a", "b"}, false}},
+		{"no trailing newline", "a\nb", false, LineText{[]string{"a", "b"}, true}},
+		{"crlf kept", "a\r\nb\r\n", false, LineText{[]string{"a\r", "b\r"}, false}},
+		{"crlf and cr with eol", "a\r\nb\rc\n", true, LineText{[]string{"a", "b", "c"}, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitLines(tt.text, tt.eol); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestCompareLines tests the line ops of a line diff
+func TestCompareLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		text1    string
+		text2    string
+		opts     Options
+		expected []LineOp
+	}{
+		{"identical", "a\nb\n", "a\nb\n", Options{}, []LineOp{{' ', 0, 0}, {' ', 1, 1}}},
+		{"changed line", "a\nb\nc\n", "a\nX\nc\n", Options{},
+			[]LineOp{{' ', 0, 0}, {'-', 1, 1}, {'+', 2, 1}, {' ', 2, 2}}},
+		{"removed before added", "a\nb\n", "c\nd\n", Options{},
+			[]LineOp{{'-', 0, 0}, {'-', 1, 0}, {'+', 2, 0}, {'+', 2, 1}}},
+		{"case insensitive", "A\nb\n", "a\nB\n", Options{CaseInsensitive: true}, []LineOp{{' ', 0, 0}, {' ', 1, 1}}},
+		{"missing final newline", "a\nb\n", "a\nb", Options{}, []LineOp{{' ', 0, 0}, {'-', 1, 1}, {'+', 2, 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CompareLines(SplitLines(tt.text1, false), SplitLines(tt.text2, false), tt.opts)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	ops := []LineOp{{' ', 0, 0}, {'-', 1, 1}, {'-', 2, 1}, {'+', 3, 1}, {' ', 3, 2}}
+	if removed, added := CountLineChanges(ops); removed != 2 || added != 1 {
+		t.Errorf("Expected 2 removed and 1 added, got %d and %d", removed, added)
+	}
+	if end, mid := ChangeBlock(ops, 1); end != 4 || mid != 3 {
+		t.Errorf("Expected a change block ending at 4 with its first addition at 3, got %d and %d", end, mid)
+	}
+}
+
+// TestGroupHunks tests the grouping and headers of unified diff hunks
+func TestGroupHunks(t *testing.T) {
+	lines := func(n int, changed ...int) string {
+		text := ""
+		for i := 1; i <= n; i++ {
+			line := "line"
+			for _, c := range changed {
+				if c == i {
+					line = "changed"
+				}
+			}
+			text += line + string(rune('a'+i-1)) + "\n"
+		}
+		return text
+	}
+	tests := []struct {
+		name     string
+		text1    string
+		text2    string
+		context  int
+		expected []Hunk
+	}{
+		{"identical", lines(5), lines(5), 3, nil},
+		{"one change", lines(10), lines(10, 5), 3, []Hunk{{1, 9, 2, 7, 2, 7}}},
+		{"changes merged", lines(12), lines(12, 3, 7), 2, []Hunk{{0, 11, 1, 9, 1, 9}}},
+		{"changes 2*context apart merged", lines(12), lines(12, 3, 8), 2, []Hunk{{0, 12, 1, 10, 1, 10}}},
+		{"changes apart", lines(20), lines(20, 3, 15), 1, []Hunk{{1, 5, 2, 3, 2, 3}, {14, 18, 14, 3, 14, 3}}},
+		{"no context", lines(5), lines(5, 3), 0, []Hunk{{2, 4, 3, 1, 3, 1}}},
+		{"no context apart", lines(5), lines(5, 2, 4), 0, []Hunk{{1, 3, 2, 1, 2, 1}, {4, 6, 4, 1, 4, 1}}},
+		{"pure insertion", "a\nb\n", "a\nX\nb\n", 1, []Hunk{{0, 3, 1, 2, 1, 3}}},
+		{"into empty", "", "a\n", 3, []Hunk{{0, 1, 0, 0, 1, 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := CompareLines(SplitLines(tt.text1, false), SplitLines(tt.text2, false), Options{})
+			if got := GroupHunks(ops, tt.context); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestIntraLineSegments tests splitting a changed pair of lines into changed
+// and unchanged runs
+func TestIntraLineSegments(t *testing.T) {
+	segs1, segs2 := IntraLineSegments("the cat sat", "the hat sat")
+	expected1 := []Segment{{[]rune("the "), false}, {[]rune("c"), true}, {[]rune("at sat"), false}}
+	expected2 := []Segment{{[]rune("the "), false}, {[]rune("h"), true}, {[]rune("at sat"), false}}
+	if !reflect.DeepEqual(segs1, expected1) || !reflect.DeepEqual(segs2, expected2) {
+		t.Errorf("Expected %v and %v, got %v and %v", expected1, expected2, segs1, segs2)
+	}
+
+	segs1, segs2 = IntraLineSegments("abc", "abcdef")
+	expected1 = []Segment{{[]rune("abc"), false}}
+	expected2 = []Segment{{[]rune("abc"), false}, {[]rune("def"), true}}
+	if !reflect.DeepEqual(segs1, expected1) || !reflect.DeepEqual(segs2, expected2) {
+		t.Errorf("Expected %v and %v, got %v and %v", expected1, expected2, segs1, segs2)
+	}
+}
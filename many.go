@@ -0,0 +1,48 @@
+package changecase
+
+// CompareMany - compare every string against a reference string, which is
+// the first string or, with majority set, the first string holding the most
+// common value (ties go to the value that appears first). It returns the
+// index of the reference and the result of comparing it with each string.
+func CompareMany(strs []string, majority bool, opts Options) (int, []Result) {
+	ref := 0
+	if majority {
+		ref = majorityIndex(strs, opts)
+	}
+	results := make([]Result, len(strs))
+	for i, str := range strs {
+		results[i] = Compare(strs[ref], str, opts)
+	}
+	return ref, results
+}
+
+// majorityIndex - return the index of the first string holding the most
+// common value
+func majorityIndex(strs []string, opts Options) int {
+	keys := make([]string, len(strs))
+	counts := make(map[string]int)
+	for i, str := range strs {
+		runes, _ := Prepare(str, opts)
+		keys[i] = string(runes)
+		counts[keys[i]]++
+	}
+
+	best := 0
+	for i, key := range keys {
+		if counts[key] > counts[keys[best]] {
+			best = i
+		}
+	}
+	return best
+}
+
+// CountMismatches - return the number of results that are not a match
+func CountMismatches(results []Result) int {
+	mismatches := 0
+	for _, res := range results {
+		if !res.Equal() {
+			mismatches++
+		}
+	}
+	return mismatches
+}
@@ -0,0 +1,38 @@
+package changecase
+
+import "testing"
+
+// TestCompareMany tests comparing many strings against the first string or
+// the majority
+func TestCompareMany(t *testing.T) {
+	tests := []struct {
+		name       string
+		strs       []string
+		majority   bool
+		opts       Options
+		ref        int
+		mismatches int
+	}{
+		{"all equal", []string{"a", "a", "a"}, false, Options{}, 0, 0},
+		{"against the first", []string{"x", "a", "a"}, false, Options{}, 0, 2},
+		{"against the majority", []string{"x", "a", "a"}, true, Options{}, 1, 1},
+		{"majority tie goes first", []string{"x", "a", "x", "a"}, true, Options{}, 0, 2},
+		{"majority with options", []string{"x", "A", "a"}, true, Options{CaseInsensitive: true}, 1, 1},
+		{"majority without options", []string{"x", "A", "a"}, true, Options{}, 0, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, results := CompareMany(tt.strs, tt.majority, tt.opts)
+			if ref != tt.ref {
+				t.Errorf("Expected reference %d, got %d", tt.ref, ref)
+			}
+			if len(results) != len(tt.strs) || !results[ref].Equal() {
+				t.Fatalf("Expected a result per string with the reference equal to itself, got %+v", results)
+			}
+			if n := CountMismatches(results); n != tt.mismatches {
+				t.Errorf("Expected %d mismatches, got %d", tt.mismatches, n)
+			}
+		})
+	}
+}
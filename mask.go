@@ -0,0 +1,111 @@
+package changecase
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Mask - a regular expression whose matches are replaced by a placeholder
+// before two strings are compared, so that volatile content such as
+// timestamps and identifiers does not count as a difference
+type Mask struct {
+	Name        string
+	Pattern     *regexp.Regexp
+	Placeholder string
+}
+
+// octet - one 0-255 component of an IPv4 address
+const octet = `(?:25[0-5]|2[0-4][0-9]|1[0-9][0-9]|[1-9]?[0-9])`
+
+// NamedMasks - the built-in masks, in display order
+var NamedMasks = []Mask{
+	{"uuid", regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<uuid>"},
+	{"iso8601", regexp.MustCompile(`\b[0-9]{4}-[0-9]{2}-[0-9]{2}(?:[T ][0-9]{2}:[0-9]{2}(?::[0-9]{2}(?:[.,][0-9]+)?)?(?:Z|[+-][0-9]{2}(?::?[0-9]{2})?)?)?`), "<iso8601>"},
+	{"hexaddr", regexp.MustCompile(`\b0[xX][0-9a-fA-F]+\b`), "<hexaddr>"},
+	{"ipv4", regexp.MustCompile(`\b` + octet + `(?:\.` + octet + `){3}\b`), "<ipv4>"},
+}
+
+// ParseMask - return the built-in mask with the given (case-insensitive) name
+func ParseMask(name string) (Mask, error) {
+	for _, mask := range NamedMasks {
+		if strings.EqualFold(name, mask.Name) {
+			return mask, nil
+		}
+	}
+	return Mask{}, fmt.Errorf("unknown mask %q", name)
+}
+
+// NewMask - return a mask for a regular expression in RE2 syntax, whose
+// matches are replaced by "<ignored>"
+func NewMask(expr string) (Mask, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return Mask{}, err
+	}
+	return Mask{Name: expr, Pattern: re, Placeholder: "<ignored>"}, nil
+}
+
+// MaskedRegion - a 0-based half-open rune range of a string that was masked
+type MaskedRegion struct {
+	Mask  *Mask
+	Start int
+	End   int
+}
+
+// FindMasked - return the regions of s matched by masks, in order. Masks are
+// applied in the order given, and a match overlapping a region claimed by an
+// earlier mask is ignored. Empty matches are never masked.
+func FindMasked(s string, masks []Mask) []MaskedRegion {
+	var regions []MaskedRegion
+	for m := range masks {
+		for _, loc := range masks[m].Pattern.FindAllStringIndex(s, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+			start := utf8.RuneCountInString(s[:loc[0]])
+			end := start + utf8.RuneCountInString(s[loc[0]:loc[1]])
+			overlaps := false
+			for _, r := range regions {
+				if start < r.End && r.Start < end {
+					overlaps = true
+					break
+				}
+			}
+			if !overlaps {
+				regions = append(regions, MaskedRegion{&masks[m], start, end})
+			}
+		}
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i].Start < regions[j].Start })
+	return regions
+}
+
+// ApplyMasks - replace every region of s matched by masks with the mask's
+// placeholder, returning the masked runes and a map from each masked rune to
+// the index of the rune in s it came from (the start of the region, for
+// placeholder runes), plus a final entry of len([]rune(s))
+func ApplyMasks(s string, masks []Mask) ([]rune, []int) {
+	runes := []rune(s)
+	out := make([]rune, 0, len(runes))
+	origin := make([]int, 0, len(runes)+1)
+	i := 0
+	for _, region := range FindMasked(s, masks) {
+		for ; i < region.Start; i++ {
+			out = append(out, runes[i])
+			origin = append(origin, i)
+		}
+		for _, r := range region.Mask.Placeholder {
+			out = append(out, r)
+			origin = append(origin, region.Start)
+		}
+		i = region.End
+	}
+	for ; i < len(runes); i++ {
+		out = append(out, runes[i])
+		origin = append(origin, i)
+	}
+	return out, append(origin, len(runes))
+}
@@ -0,0 +1,112 @@
+package changecase
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestNamedMasks tests what each built-in mask matches
+func TestNamedMasks(t *testing.T) {
+	tests := []struct {
+		mask     string
+		text     string
+		expected string
+	}{
+		{"uuid", "id=123e4567-e89b-12d3-a456-426614174000 ok", "id=<uuid> ok"},
+		{"uuid", "ID 123E4567-E89B-12D3-A456-426614174000", "ID <uuid>"},
+		{"uuid", "not-a-uuid-123e4567", "not-a-uuid-123e4567"},
+		{"iso8601", "at 2024-03-01T12:34:56Z done", "at <iso8601> done"},
+		{"iso8601", "at 2024-03-01 12:34:56.789+01:00 done", "at <iso8601> done"},
+		{"iso8601", "on 2024-03-01.", "on <iso8601>."},
+		{"hexaddr", "ptr 0x7ffd5c1e and 0XDEAD", "ptr <hexaddr> and <hexaddr>"},
+		{"hexaddr", "0xZZ", "0xZZ"},
+		{"ipv4", "from 192.168.1.10:8080", "from <ipv4>:8080"},
+		{"ipv4", "version 1.2.3", "version 1.2.3"},
+		{"ipv4", "bad 256.1.1.1", "bad 256.1.1.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mask+" "+tt.text, func(t *testing.T) {
+			mask, err := ParseMask(tt.mask)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _ := ApplyMasks(tt.text, []Mask{mask})
+			if string(got) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, string(got))
+			}
+		})
+	}
+
+	if _, err := ParseMask("email"); err == nil {
+		t.Errorf("Expected an error for an unknown mask")
+	}
+}
+
+// TestApplyMasks tests the origin map and overlapping masks
+func TestApplyMasks(t *testing.T) {
+	custom, err := NewMask(`pid=[0-9]+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hexaddr, _ := ParseMask("hexaddr")
+
+	got, origin := ApplyMasks("é pid=42 x", []Mask{custom})
+	if string(got) != "é <ignored> x" {
+		t.Errorf("Expected %q, got %q", "é <ignored> x", string(got))
+	}
+	expected := []int{0, 1, 2, 2, 2, 2, 2, 2, 2, 2, 2, 8, 9, 10}
+	if !reflect.DeepEqual(origin, expected) {
+		t.Errorf("Expected origin %v, got %v", expected, origin)
+	}
+
+	// the first mask claims the region, so the overlapping hexaddr is skipped
+	regions := FindMasked("pid=0x10 0x20", []Mask{custom, hexaddr})
+	if len(regions) != 2 || regions[0].Mask.Name != custom.Name || regions[1].Start != 9 || regions[1].End != 13 {
+		t.Errorf("Unexpected regions %+v", regions)
+	}
+
+	if _, err := NewMask("("); err == nil {
+		t.Errorf("Expected an error for an invalid regexp")
+	}
+}
+
+// TestMaskedCompare tests comparing log lines with several masks, and the
+// positions reported around masked regions
+func TestMaskedCompare(t *testing.T) {
+	log1 := "2024-03-01T10:00:00Z req 123e4567-e89b-12d3-a456-426614174000 from 10.0.0.1 at 0x7ffd5c1e ok"
+	log2 := "2025-11-30T23:59:59.123+01:00 req 9b2e4567-e89b-12d3-a456-4266141749ff from 192.168.1.20 at 0xdeadbeef ok"
+	masks := func(names ...string) []Mask {
+		var list []Mask
+		for _, name := range names {
+			mask, err := ParseMask(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			list = append(list, mask)
+		}
+		return list
+	}
+
+	tests := []struct {
+		name     string
+		str1     string
+		str2     string
+		masks    []Mask
+		position int
+	}{
+		{"unmasked", log1, log2, nil, 4},
+		{"all masks", log1, log2, masks("uuid", "iso8601", "ipv4", "hexaddr"), 0},
+		{"missing mask", log1, log2, masks("uuid", "iso8601", "ipv4"), 82},
+		{"position after mask", "id 123e4567-e89b-12d3-a456-426614174000 ok", "id 9b2e4567-e89b-12d3-a456-4266141749ff OK", masks("uuid"), 41},
+		{"different masks differ", "x 10.0.0.1", "x 123e4567-e89b-12d3-a456-426614174000", masks("uuid", "ipv4"), 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if res := Compare(tt.str1, tt.str2, Options{Masks: tt.masks}); res.Position1.Rune != tt.position {
+				t.Errorf("Expected position %d, got %d", tt.position, res.Position1.Rune)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package changecase
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MatchMode - how a pattern is matched against a string
+type MatchMode string
+
+const (
+	MatchPrefix   MatchMode = "prefix"
+	MatchSuffix   MatchMode = "suffix"
+	MatchContains MatchMode = "contains"
+	MatchGlob     MatchMode = "glob"
+	MatchRegex    MatchMode = "regex"
+)
+
+// MatchModes - every supported match mode, in display order
+var MatchModes = []MatchMode{MatchPrefix, MatchSuffix, MatchContains, MatchGlob, MatchRegex}
+
+// ParseMatchMode - return the MatchMode with the given (case-insensitive) name
+func ParseMatchMode(name string) (MatchMode, error) {
+	for _, mode := range MatchModes {
+		if strings.EqualFold(name, string(mode)) {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("unknown match mode %q", name)
+}
+
+// MatchRunes - report whether text matches pattern in the given mode, along
+// with the 0-based half-open rune span of the match. Glob patterns must
+// match the whole text; regular expressions use RE2 syntax and report the
+// leftmost match. An error is only returned for an invalid regexp.
+func MatchRunes(text, pattern []rune, mode MatchMode) (int, int, bool, error) {
+	switch mode {
+	case MatchPrefix:
+		if len(pattern) <= len(text) && string(text[:len(pattern)]) == string(pattern) {
+			return 0, len(pattern), true, nil
+		}
+	case MatchSuffix:
+		start := len(text) - len(pattern)
+		if start >= 0 && string(text[start:]) == string(pattern) {
+			return start, len(text), true, nil
+		}
+	case MatchContains:
+		if idx := strings.Index(string(text), string(pattern)); idx >= 0 {
+			start := utf8.RuneCountInString(string(text)[:idx])
+			return start, start + len(pattern), true, nil
+		}
+	case MatchGlob:
+		if GlobMatch(pattern, text) {
+			return 0, len(text), true, nil
+		}
+	case MatchRegex:
+		re, err := regexp.Compile(string(pattern))
+		if err != nil {
+			return 0, 0, false, err
+		}
+		str := string(text)
+		if loc := re.FindStringIndex(str); loc != nil {
+			start := utf8.RuneCountInString(str[:loc[0]])
+			return start, start + utf8.RuneCountInString(str[loc[0]:loc[1]]), true, nil
+		}
+	}
+	return 0, 0, false, nil
+}
+
+// MatchStrings - match pattern against text in the given mode, honoring the
+// case, whitespace and skeleton options, and return the 0-based half-open
+// rune span of the match within the original text. A regexp is never
+// rewritten, as that could change its meaning; it is only made
+// case-insensitive.
+func MatchStrings(text, pattern string, mode MatchMode, opts Options) (int, int, bool, error) {
+	textRunes, origin := Prepare(text, opts)
+
+	var patternRunes []rune
+	if mode == MatchRegex {
+		if opts.CaseInsensitive {
+			pattern = "(?i)" + pattern
+		}
+		patternRunes = []rune(pattern)
+	} else {
+		patternRunes, _ = Prepare(pattern, opts)
+	}
+
+	start, end, matched, err := MatchRunes(textRunes, patternRunes, mode)
+	if err != nil || !matched {
+		return 0, 0, false, err
+	}
+	start, end = originRange(origin, start, end)
+	return start, end, true, nil
+}
+
+// GlobMatch - report whether text matches a shell glob pattern as a whole.
+// "*" matches any run of runes (including "/"), "?" matches a single rune,
+// "[abc]", "[a-z]" and "[!a-z]" (or "[^a-z]") match rune classes, and a
+// backslash matches the following rune literally.
+func GlobMatch(pattern, text []rune) bool {
+	p, t := 0, 0
+	starP, starT := -1, 0
+	for t < len(text) {
+		if p < len(pattern) {
+			if pattern[p] == '*' {
+				// remember the star so we can backtrack to it
+				starP, starT = p, t
+				p++
+				continue
+			}
+			if next, ok := globMatchOne(pattern, p, text[t]); ok {
+				p = next
+				t++
+				continue
+			}
+		}
+		if starP < 0 {
+			return false
+		}
+		// let the last star swallow one more rune and try again
+		starT++
+		p, t = starP+1, starT
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// globMatchOne - match the single-rune pattern element at pattern[p] against
+// r, returning the index of the next element and whether r matched
+func globMatchOne(pattern []rune, p int, r rune) (int, bool) {
+	switch pattern[p] {
+	case '?':
+		return p + 1, true
+	case '\\':
+		if p+1 < len(pattern) {
+			return p + 2, pattern[p+1] == r
+		}
+	case '[':
+		if next, matched, valid := globMatchClass(pattern, p, r); valid {
+			return next, matched
+		}
+	}
+	return p + 1, pattern[p] == r
+}
+
+// globMatchClass - match the "[...]" class starting at pattern[p] against r.
+// valid is false when the class is not terminated, in which case the "["
+// is an ordinary rune.
+func globMatchClass(pattern []rune, p int, r rune) (next int, matched bool, valid bool) {
+	i := p + 1
+	negate := false
+	if i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^') {
+		negate = true
+		i++
+	}
+	first := true
+	for i < len(pattern) && (first || pattern[i] != ']') {
+		first = false
+		lo := pattern[i]
+		if lo == '\\' && i+1 < len(pattern) {
+			i++
+			lo = pattern[i]
+		}
+		hi := lo
+		if i+2 < len(pattern) && pattern[i+1] == '-' && pattern[i+2] != ']' {
+			hi = pattern[i+2]
+			i += 2
+		}
+		if lo <= r && r <= hi {
+			matched = true
+		}
+		i++
+	}
+	if i >= len(pattern) {
+		return p + 1, false, false
+	}
+	return i + 1, matched != negate, true
+}
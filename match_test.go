@@ -0,0 +1,112 @@
+package changecase
+
+import "testing"
+
+// TestGlobMatch tests shell glob matching against whole strings
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		text     string
+		expected bool
+	}{
+		{"*", "", true},
+		{"*", "anything/at/all", true},
+		{"report-*.csv", "report-2024.csv", true},
+		{"report-*.csv", "report-2024.tsv", false},
+		{"a?c", "abc", true},
+		{"a?c", "ac", false},
+		{"[abc]x", "bx", true},
+		{"[a-c]x", "dx", false},
+		{"[!a-c]x", "dx", true},
+		{"[^a-c]x", "ax", false},
+		{"*.[ct]sv", "data.tsv", true},
+		{`\*literal`, "*literal", true},
+		{`\*literal`, "xliteral", false},
+		{"[unterminated", "[unterminated", true},
+		{"日*語", "日本語", true},
+		{"a*b*c", "aXbYbZc", true},
+		{"a*b*c", "aXbYbZ", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.text, func(t *testing.T) {
+			if got := GlobMatch([]rune(tt.pattern), []rune(tt.text)); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestMatchRunes tests the match span reported by each mode
+func TestMatchRunes(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		pattern string
+		mode    MatchMode
+		start   int
+		end     int
+		matched bool
+	}{
+		{"prefix", "hello world", "hello", MatchPrefix, 0, 5, true},
+		{"prefix too long", "hi", "hello", MatchPrefix, 0, 0, false},
+		{"suffix", "hello world", "world", MatchSuffix, 6, 11, true},
+		{"contains unicode", "日本語テキスト", "語テ", MatchContains, 2, 4, true},
+		{"contains missing", "hello", "xyz", MatchContains, 0, 0, false},
+		{"glob", "report.csv", "*.csv", MatchGlob, 0, 10, true},
+		{"regex", "build 1234 ok", "[0-9]+", MatchRegex, 6, 10, true},
+		{"regex after unicode", "héllo 42", `\d+`, MatchRegex, 6, 8, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, matched, err := MatchRunes([]rune(tt.text), []rune(tt.pattern), tt.mode)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if matched != tt.matched || start != tt.start || end != tt.end {
+				t.Errorf("Expected %v %d-%d, got %v %d-%d", tt.matched, tt.start, tt.end, matched, start, end)
+			}
+		})
+	}
+
+	if _, _, _, err := MatchRunes([]rune("a"), []rune("("), MatchRegex); err == nil {
+		t.Errorf("Expected an error for an invalid regexp, got none")
+	}
+}
+
+// TestMatchStrings tests that options apply to the text and pattern and
+// that the span refers to the original text
+func TestMatchStrings(t *testing.T) {
+	collapse := Options{CaseInsensitive: true, Whitespace: WhitespaceOptions{Collapse: true}}
+	tests := []struct {
+		name    string
+		text    string
+		pattern string
+		mode    MatchMode
+		opts    Options
+		start   int
+		end     int
+		matched bool
+	}{
+		{"plain", "hello world", "world", MatchSuffix, Options{}, 6, 11, true},
+		{"case insensitive", "Hello World", "WORLD", MatchContains, Options{CaseInsensitive: true}, 6, 11, true},
+		{"collapsed span in original runes", "Hello   World", "hello  world", MatchContains, collapse, 0, 13, true},
+		{"span after collapsed space", "a   Bc", "c", MatchSuffix, collapse, 5, 6, true},
+		{"regex case insensitive", "ABC", "b", MatchRegex, Options{CaseInsensitive: true}, 1, 2, true},
+		{"regex not collapsed", "a   b", "a  b", MatchRegex, collapse, 0, 0, false},
+		{"no match", "hello", "world", MatchContains, Options{}, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, matched, err := MatchStrings(tt.text, tt.pattern, tt.mode, tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if matched != tt.matched || start != tt.start || end != tt.end {
+				t.Errorf("Expected %v %d-%d, got %v %d-%d", tt.matched, tt.start, tt.end, matched, start, end)
+			}
+		})
+	}
+}
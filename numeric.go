@@ -0,0 +1,146 @@
+package changecase
+
+import (
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tolerance - how far apart two numbers may be and still be considered
+// equal. As with Python's math.isclose, numbers are close when either bound
+// is met; with both bounds at zero they must be exactly equal.
+type Tolerance struct {
+	Absolute float64 // maximum absolute difference
+	Relative float64 // maximum difference relative to the larger magnitude
+}
+
+// Close - report whether x and y are equal within the tolerance
+func (tol Tolerance) Close(x, y float64) bool {
+	if x == y {
+		return true
+	}
+	diff := math.Abs(x - y)
+	return diff <= tol.Absolute || diff <= tol.Relative*math.Max(math.Abs(x), math.Abs(y))
+}
+
+// numbersEqual - compare two numbers exactly (so 1, 1.0 and 1e0 are equal),
+// or within tolerance when it is set
+func numbersEqual(a, b string, tolerance Tolerance) bool {
+	if tolerance != (Tolerance{}) {
+		f1, err1 := strconv.ParseFloat(a, 64)
+		f2, err2 := strconv.ParseFloat(b, 64)
+		if err1 == nil && err2 == nil {
+			return tolerance.Close(f1, f2)
+		}
+	}
+	r1, ok1 := new(big.Rat).SetString(a)
+	r2, ok2 := new(big.Rat).SetString(b)
+	if !ok1 || !ok2 {
+		return a == b
+	}
+	return r1.Cmp(r2) == 0
+}
+
+// Token - a number, word, whitespace run or single other rune of a string,
+// with its 0-based half-open rune range
+type Token struct {
+	Text   string
+	Start  int
+	End    int
+	Number bool
+	Value  float64 // the parsed value of a number token
+}
+
+// tokenPattern - dotted runs such as versions and IPv4 addresses, numbers
+// (with an optional sign, decimals and exponents), words, whitespace runs and
+// any other single rune
+var tokenPattern = regexp.MustCompile(`[-+]?[0-9]*(?:\.[0-9]+){2,}|[-+]?(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+)(?:[eE][-+]?[0-9]+)?|[\p{L}\p{M}_][\p{L}\p{M}\p{N}_]*|\s+|.`)
+
+// Tokenize - split s into tokens for CompareTokens
+func Tokenize(s string) []Token {
+	var tokens []Token
+	pos := 0
+	prevWord := false
+	for _, loc := range tokenPattern.FindAllStringIndex(s, -1) {
+		text := s[loc[0]:loc[1]]
+		// a sign directly after a word or number, as in "x-1", is an operator
+		if prevWord && (text[0] == '-' || text[0] == '+') && len(text) > 1 {
+			tokens = append(tokens, Token{Text: text[:1], Start: pos, End: pos + 1})
+			pos++
+			text = text[1:]
+		}
+		// a "." directly after digits or another ".", as in "v1.5" or "1..5",
+		// is not a decimal point, so ".5" and ".50" are not read as equal
+		start := loc[1] - len(text)
+		if start > 0 && strings.IndexByte("0123456789.", s[start-1]) >= 0 && len(text) > 1 && text[0] == '.' && strings.Count(text, ".") == 1 {
+			tokens = append(tokens, Token{Text: ".", Start: pos, End: pos + 1})
+			pos++
+			text = text[1:]
+		}
+		n := utf8.RuneCountInString(text)
+		token := Token{Text: text, Start: pos, End: pos + n}
+		// words never start with a digit, so "Inf" and "NaN" stay words
+		if value, err := strconv.ParseFloat(text, 64); err == nil && strings.ContainsAny(text[:1], "+-.0123456789") {
+			token.Number, token.Value = true, value
+		}
+		tokens = append(tokens, token)
+		r, _ := utf8.DecodeLastRuneInString(text)
+		prevWord = r == '_' || unicode.In(r, unicode.L, unicode.M, unicode.N)
+		pos += n
+	}
+	return tokens
+}
+
+// TokenDifference - the first pair of tokens that differ between two strings;
+// Index is the 0-based token index and a nil token means that string ran out
+type TokenDifference struct {
+	Index  int
+	Token1 *Token
+	Token2 *Token
+}
+
+// Positions - return the 1-based rune positions in a and b at which the
+// differing tokens start, or one past the end of a string that ran out
+func (d *TokenDifference) Positions(a, b string) (int, int) {
+	position1, position2 := utf8.RuneCountInString(a)+1, utf8.RuneCountInString(b)+1
+	if d.Token1 != nil {
+		position1 = d.Token1.Start + 1
+	}
+	if d.Token2 != nil {
+		position2 = d.Token2.Start + 1
+	}
+	return position1, position2
+}
+
+// CompareTokens - compare two strings token by token, numbers within tol and
+// everything else exactly (or case-insensitively when foldCase is set), and
+// return the first difference or nil when they are equal
+func CompareTokens(a, b string, tol Tolerance, foldCase bool) *TokenDifference {
+	tokens1, tokens2 := Tokenize(a), Tokenize(b)
+	for i := 0; i < len(tokens1) || i < len(tokens2); i++ {
+		if i >= len(tokens1) {
+			return &TokenDifference{Index: i, Token2: &tokens2[i]}
+		}
+		if i >= len(tokens2) {
+			return &TokenDifference{Index: i, Token1: &tokens1[i]}
+		}
+		t1, t2 := &tokens1[i], &tokens2[i]
+		switch {
+		case t1.Number && t2.Number:
+			if !numbersEqual(t1.Text, t2.Text, tol) {
+				return &TokenDifference{Index: i, Token1: t1, Token2: t2}
+			}
+		case foldCase:
+			if strings.Map(FoldRune, t1.Text) != strings.Map(FoldRune, t2.Text) {
+				return &TokenDifference{Index: i, Token1: t1, Token2: t2}
+			}
+		case t1.Text != t2.Text:
+			return &TokenDifference{Index: i, Token1: t1, Token2: t2}
+		}
+	}
+	return nil
+}
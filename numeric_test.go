@@ -0,0 +1,126 @@
+package changecase
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestTokenize tests how strings are split into number and other tokens
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []string
+		numbers  []bool
+	}{
+		{"x = 1.5e-3;", []string{"x", " ", "=", " ", "1.5e-3", ";"}, []bool{false, false, false, false, true, false}},
+		{"-2 +.5", []string{"-2", " ", "+.5"}, []bool{true, false, true}},
+		{"x-1", []string{"x", "-", "1"}, []bool{false, false, true}},
+		{"1-2", []string{"1", "-", "2"}, []bool{true, false, true}},
+		{"v1.2.3 10.0.0.1", []string{"v1", ".2.3", " ", "10.0.0.1"}, []bool{false, false, false, false}},
+		{"v1.5 1..5", []string{"v1", ".", "5", " ", "1.", ".", "5"}, []bool{false, false, true, false, true, false, true}},
+		{"Inf v2 café", []string{"Inf", " ", "v2", " ", "café"}, []bool{false, false, false, false, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			var texts []string
+			var numbers []bool
+			for _, token := range Tokenize(tt.text) {
+				texts = append(texts, token.Text)
+				numbers = append(numbers, token.Number)
+			}
+			if !reflect.DeepEqual(texts, tt.expected) || !reflect.DeepEqual(numbers, tt.numbers) {
+				t.Errorf("Expected %q %v, got %q %v", tt.expected, tt.numbers, texts, numbers)
+			}
+		})
+	}
+}
+
+// TestCompareTokens tests numeric tolerance and the reported token
+func TestCompareTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		str1     string
+		str2     string
+		tol      Tolerance
+		foldCase bool
+		index    int // -1 when the strings are equal
+		start    int
+	}{
+		{"exact numbers", "t=1.0 s", "t=1 s", Tolerance{}, false, -1, 0},
+		{"exact mismatch", "t=1.0000001 s", "t=1.0 s", Tolerance{}, false, 2, 2},
+		{"beyond float64", "9007199254740993", "9007199254740992", Tolerance{}, false, 0, 0},
+		{"version", "v1.2.3", "v1.2.30", Tolerance{}, false, 1, 2},
+		{"ipv4 address", "host 10.0.0.1 up", "host 10.0.0.10 up", Tolerance{}, false, 2, 5},
+		{"decimal after a word", "v1.5", "v1.50", Tolerance{}, false, 2, 3},
+		{"absolute", "t=1.0000001 s", "t=1.0 s", Tolerance{Absolute: 1e-6}, false, -1, 0},
+		{"relative", "n=1000001", "n=1000000", Tolerance{Relative: 1e-5}, false, -1, 0},
+		{"relative too small", "n=1000100", "n=1000000", Tolerance{Relative: 1e-5}, false, 2, 2},
+		{"word differs", "1.0 apples", "1.0 pears", Tolerance{Absolute: 1}, false, 2, 4},
+		{"fold case", "1.0 Apples", "1.0 apples", Tolerance{}, true, -1, 0},
+		{"fold case as FoldRune", "1 \u017f", "1 s", Tolerance{}, true, 2, 2},
+		{"kelvin sign", "1 \u212a", "1 k", Tolerance{}, true, -1, 0},
+		{"number vs word", "x 1", "x one", Tolerance{Absolute: 1}, false, 2, 2},
+		{"shorter", "1 2", "1 2 3", Tolerance{}, false, 3, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := CompareTokens(tt.str1, tt.str2, tt.tol, tt.foldCase)
+			if tt.index < 0 {
+				if d != nil {
+					t.Errorf("Expected no difference, got token %d", d.Index)
+				}
+				return
+			}
+			if d == nil {
+				t.Fatalf("Expected a difference at token %d, got none", tt.index)
+			}
+			start := -1
+			if d.Token2 != nil {
+				start = d.Token2.Start
+			}
+			if d.Index != tt.index || start != tt.start {
+				t.Errorf("Expected token %d at %d, got token %d at %d", tt.index, tt.start, d.Index, start)
+			}
+		})
+	}
+}
+
+// TestTokenDifferencePositions tests the rune positions of differing
+// tokens, including a string that ran out of tokens
+func TestTokenDifferencePositions(t *testing.T) {
+	tests := []struct {
+		str1, str2           string
+		position1, position2 int
+	}{
+		{"日本 x=1", "日本 x=2", 6, 6},
+		{"a  1", "a 1", 2, 2},
+		{"1.0 x", "1 x y", 6, 4},
+		{"1 x y", "1.0 x", 4, 6},
+	}
+
+	for _, tt := range tests {
+		d := CompareTokens(tt.str1, tt.str2, Tolerance{}, false)
+		if d == nil {
+			t.Fatalf("%q vs %q: expected a difference, got none", tt.str1, tt.str2)
+		}
+		if position1, position2 := d.Positions(tt.str1, tt.str2); position1 != tt.position1 || position2 != tt.position2 {
+			t.Errorf("%q vs %q: expected positions %d and %d, got %d and %d",
+				tt.str1, tt.str2, tt.position1, tt.position2, position1, position2)
+		}
+	}
+}
+
+// TestToleranceClose tests the absolute and relative bounds
+func TestToleranceClose(t *testing.T) {
+	if !(Tolerance{}).Close(0.5, 0.5) || (Tolerance{}).Close(0.5, 0.50001) {
+		t.Errorf("Zero tolerance must only accept equal numbers")
+	}
+	if !(Tolerance{Absolute: 0.1}).Close(1, 1.05) || (Tolerance{Absolute: 0.1}).Close(1, 1.2) {
+		t.Errorf("Absolute tolerance is not applied")
+	}
+	if !(Tolerance{Relative: 0.01}).Close(100, 100.5) || (Tolerance{Relative: 0.01}).Close(1, 1.5) {
+		t.Errorf("Relative tolerance is not applied")
+	}
+}
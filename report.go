@@ -0,0 +1,547 @@
+package changecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Units in which a mismatch position can be printed
+const (
+	UnitRune    = "rune"     // 1-based rune index
+	UnitByte    = "byte"     // 1-based byte index
+	UnitColumn  = "column"   // display column, wide runes counting as two
+	UnitLineCol = "line:col" // line number and rune column within the line
+)
+
+// ParseUnit - validate the name of a position unit
+func ParseUnit(name string) (string, error) {
+	switch name {
+	case UnitRune, UnitByte, UnitColumn, UnitLineCol:
+		return name, nil
+	}
+	return "", fmt.Errorf("unknown unit %q (expected rune, byte, column or line:col)", name)
+}
+
+// FormatPosition - return a mismatch position in the given unit
+func FormatPosition(pos Position, unit string) string {
+	switch unit {
+	case UnitByte:
+		return fmt.Sprintf("%d", pos.Byte)
+	case UnitColumn:
+		return fmt.Sprintf("%d", pos.Column)
+	case UnitLineCol:
+		return fmt.Sprintf("%d:%d", pos.Line, pos.LineColumn)
+	}
+	return fmt.Sprintf("%d", pos.Rune)
+}
+
+// Escape sequences used to highlight verbose and diff output
+const (
+	ansiReset       = "\x1b[0m"
+	ansiDiff        = "\x1b[1;31m"
+	ansiEscape      = "\x1b[36m"
+	ansiHeader      = "\x1b[1m"
+	ansiHunk        = "\x1b[36m"
+	ansiRemoved     = "\x1b[31m"
+	ansiRemovedEmph = "\x1b[7;31m"
+	ansiAdded       = "\x1b[32m"
+	ansiAddedEmph   = "\x1b[7;32m"
+)
+
+// Palette - whether reports are highlighted with ANSI colors
+type Palette struct {
+	Enabled bool
+}
+
+// NewPalette - decide whether to use colors: "always", "never", or "auto",
+// which colors only when stdout is a terminal and the NO_COLOR variable is
+// unset or empty
+func NewPalette(mode string) (Palette, error) {
+	switch mode {
+	case "always":
+		return Palette{Enabled: true}, nil
+	case "never":
+		return Palette{Enabled: false}, nil
+	case "auto":
+		if os.Getenv("NO_COLOR") != "" {
+			return Palette{Enabled: false}, nil
+		}
+		info, err := os.Stdout.Stat()
+		return Palette{Enabled: err == nil && info.Mode()&os.ModeCharDevice != 0}, nil
+	}
+	return Palette{}, fmt.Errorf("unknown color mode %q (expected auto, always or never)", mode)
+}
+
+// paint - wrap s in the given color when colors are enabled; an empty color
+// leaves s as it is
+func (p Palette) paint(color, s string) string {
+	if !p.Enabled || color == "" || s == "" {
+		return s
+	}
+	return color + s + ansiReset
+}
+
+// escapeRune - return a visible escape for control characters and for runes
+// that are invisible or easily mistaken for a plain space, or "" otherwise
+func escapeRune(r rune) string {
+	switch r {
+	case '\t':
+		return `\t`
+	case '\n':
+		return `\n`
+	case '\r':
+		return `\r`
+	case ' ':
+		return ""
+	}
+	switch {
+	case r < 0x20 || r == 0x7F:
+		return fmt.Sprintf(`\x%02x`, r)
+	case unicode.In(r, unicode.Cc, unicode.Cf, unicode.Zs, unicode.Zl, unicode.Zp):
+		return fmt.Sprintf(`\u%04x`, r)
+	}
+	return ""
+}
+
+// visible - return runes with control and invisible runes escaped (and
+// colored, when enabled) so that they can be told apart in the output
+func (p Palette) visible(runes []rune) string {
+	var b strings.Builder
+	for _, r := range runes {
+		if esc := escapeRune(r); esc != "" {
+			b.WriteString(p.paint(ansiEscape, esc))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// visibleWidth - return the display width of runes once escaped by visible
+func visibleWidth(runes []rune) int {
+	width := 0
+	for _, r := range runes {
+		if esc := escapeRune(r); esc != "" {
+			width += len(esc)
+		} else {
+			width += RuneWidth(r)
+		}
+	}
+	return width
+}
+
+// firstRune - return the first rune of s
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+// writePositionUnits - write a mismatch position in every supported unit
+func writePositionUnits(b *strings.Builder, label string, pos Position) {
+	fmt.Fprintf(b, "%s position: rune %d, byte %d (offset %d), column %d, line:col %d:%d\n",
+		label, pos.Rune, pos.Byte, pos.Offset(), pos.Column, pos.Line, pos.LineColumn)
+}
+
+// highlightSnippet - return the context of a mismatch with the differing rune
+// bracketed (or [END] past the end of the string), and the display column of
+// the bracketed rune within the returned text
+func (p Palette) highlightSnippet(snippet Snippet) (string, int) {
+	diffChar := "END"
+	if snippet.At != "" {
+		diffChar = p.visible([]rune(snippet.At))
+	}
+	before := []rune(snippet.Before)
+	caret := visibleWidth(before) + 1
+	return fmt.Sprintf("%s[%s]%s", p.visible(before), p.paint(ansiDiff, diffChar), p.visible([]rune(snippet.After))), caret
+}
+
+// describeRune - return the code point of the differing rune of a snippet
+// along with its visible form, or END past the end of the string
+func (p Palette) describeRune(snippet Snippet) string {
+	if snippet.At == "" {
+		return "END"
+	}
+	r := firstRune(snippet.At)
+	return fmt.Sprintf("U+%04X '%s'", r, p.visible([]rune{r}))
+}
+
+// writeDifference - write the runes around the mismatch of a comparison
+// result under two labels, with the differing rune bracketed and a caret
+// beneath it, followed by the code points of the two differing runes
+func (p Palette) writeDifference(b *strings.Builder, label1, label2 string, res Result) {
+	b.WriteString("Difference:\n")
+	for _, side := range []struct {
+		label   string
+		snippet Snippet
+	}{{label1, res.Context1}, {label2, res.Context2}} {
+		prefix := side.label + ": "
+		text, caret := p.highlightSnippet(side.snippet)
+		fmt.Fprintf(b, "%s%s\n", prefix, text)
+		fmt.Fprintf(b, "%s%s\n", strings.Repeat(" ", StringWidth(prefix)+caret), p.paint(ansiDiff, "^"))
+	}
+	fmt.Fprintf(b, "Code points: %s vs %s\n", p.describeRune(res.Context1), p.describeRune(res.Context2))
+}
+
+// VerboseReport - return a detailed comparison of two strings: where they
+// differ in every unit and the runes around the difference
+func (p Palette) VerboseReport(res Result) string {
+	var b strings.Builder
+	if res.Equal() {
+		b.WriteString("Strings match exactly\n")
+		return b.String()
+	}
+
+	if res.Position1.Rune == res.Position2.Rune {
+		fmt.Fprintf(&b, "Strings differ at position %d\n", res.Position1.Rune)
+	} else {
+		fmt.Fprintf(&b, "Strings differ at position %d (string 1) and %d (string 2)\n", res.Position1.Rune, res.Position2.Rune)
+	}
+	writePositionUnits(&b, "String 1", res.Position1)
+	writePositionUnits(&b, "String 2", res.Position2)
+	p.writeDifference(&b, "String 1", "String 2", res)
+	return b.String()
+}
+
+// formatRange - convert a 0-based half-open range into diff's 1-based
+// notation: "N" for a single position, "N,M" for several, and the preceding
+// position for an empty range (the point after which text is inserted or
+// deleted)
+func formatRange(start, end int) string {
+	switch end - start {
+	case 0:
+		return fmt.Sprintf("%d", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, end)
+}
+
+// AllDifferencesReport - list every differing range between two strings in
+// the style of diff's "normal" format, followed by the edit distance and the
+// similarity percentage
+func AllDifferencesReport(a, b string, opts Options, verbose bool) string {
+	diffs, stats := AllDifferences(a, b, opts)
+	runes1, runes2 := []rune(a), []rune(b)
+
+	var sb strings.Builder
+	if verbose {
+		if len(diffs) == 0 {
+			sb.WriteString("Strings match exactly\n")
+		} else {
+			fmt.Fprintf(&sb, "Strings differ in %d place(s)\n", len(diffs))
+		}
+	}
+
+	for _, d := range diffs {
+		fmt.Fprintf(&sb, "%s%s%s\n", formatRange(d.Start1, d.End1), d.Op, formatRange(d.Start2, d.End2))
+		if d.End1 > d.Start1 {
+			fmt.Fprintf(&sb, "< %q\n", string(runes1[d.Start1:d.End1]))
+		}
+		if d.End2 > d.Start2 {
+			fmt.Fprintf(&sb, "> %q\n", string(runes2[d.Start2:d.End2]))
+		}
+	}
+
+	fmt.Fprintf(&sb, "Edit distance: %d\n", stats.Distance)
+	fmt.Fprintf(&sb, "Similarity: %.2f%%\n", stats.Similarity)
+	return sb.String()
+}
+
+// ManyReport - list every string that deviates from the reference string ref
+// of CompareMany, with positions in unit, or in detail when verbose
+func (p Palette) ManyReport(count, ref int, results []Result, majority, verbose bool, unit string) string {
+	var b strings.Builder
+	outliers := CountMismatches(results)
+
+	if verbose {
+		basis := "first"
+		if majority {
+			basis = "majority"
+		}
+		fmt.Fprintf(&b, "Compared %d strings against string %d (%s)\n", count, ref+1, basis)
+		if outliers == 0 {
+			b.WriteString("All strings match exactly\n")
+			return b.String()
+		}
+		fmt.Fprintf(&b, "%d of %d strings differ\n", outliers, count)
+	} else if outliers == 0 {
+		b.WriteString("0\n")
+		return b.String()
+	}
+
+	for i, res := range results {
+		if res.Equal() {
+			continue
+		}
+		if verbose {
+			b.WriteString("\n")
+			fmt.Fprintf(&b, "String %d differs at position %d\n", i+1, res.Position2.Rune)
+			writePositionUnits(&b, fmt.Sprintf("String %d", i+1), res.Position2)
+			p.writeDifference(&b, fmt.Sprintf("String %d", ref+1), fmt.Sprintf("String %d", i+1), res)
+		} else {
+			fmt.Fprintf(&b, "string %d differs at position %s\n", i+1, FormatPosition(res.Position2, unit))
+		}
+	}
+	return b.String()
+}
+
+// MatchReport - describe whether a pattern matched text and, if so, bracket
+// the span of runes from start to end that it matched
+func (p Palette) MatchReport(text string, mode MatchMode, start, end int, matched bool) string {
+	if !matched {
+		return fmt.Sprintf("Pattern does not match (%s)\n", mode)
+	}
+	var b strings.Builder
+	if start == end {
+		fmt.Fprintf(&b, "Pattern matches (%s) with an empty match at position %d\n", mode, start+1)
+	} else {
+		fmt.Fprintf(&b, "Pattern matches (%s) at positions %d-%d\n", mode, start+1, end)
+	}
+	runes := []rune(text)
+	fmt.Fprintf(&b, "Match: %s[%s]%s\n", p.visible(runes[:start]), p.paint(ansiDiff, p.visible(runes[start:end])), p.visible(runes[end:]))
+	return b.String()
+}
+
+// FuzzyReport - describe a similarity score alongside the threshold it was
+// checked against; a negative distance is left out
+func FuzzyReport(algo Algorithm, score float64, distance int, threshold float64, matched bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Algorithm: %s\n", algo)
+	if distance >= 0 {
+		fmt.Fprintf(&b, "Distance: %d\n", distance)
+	}
+	fmt.Fprintf(&b, "Similarity: %.4f\n", score)
+	fmt.Fprintf(&b, "Threshold: %.4f\n", threshold)
+	if matched {
+		b.WriteString("Strings match within the threshold\n")
+	} else {
+		b.WriteString("Strings differ beyond the threshold\n")
+	}
+	return b.String()
+}
+
+// describeConfusable - return the code point and, when known, the Unicode
+// name of r
+func (p Palette) describeConfusable(r rune) string {
+	if name := RuneName(r); name != "" {
+		return fmt.Sprintf("U+%04X %s", r, name)
+	}
+	return fmt.Sprintf("U+%04X '%s'", r, p.visible([]rune{r}))
+}
+
+// ConfusablesReport - explain whether the first difference between two
+// strings is a pair of look-alike runes, and whether the strings would be
+// equal if every confusable rune were replaced by its prototype; it is empty
+// when the strings match
+func (p Palette) ConfusablesReport(a, b string, res Result, caseInsensitive bool) string {
+	if res.Equal() {
+		return ""
+	}
+	var sb strings.Builder
+	position := res.Position1.Rune
+	if res.Kind != MismatchRune {
+		fmt.Fprintf(&sb, "Position %d: one string ends early, which is not a confusable difference\n", position)
+	} else if r1, r2 := firstRune(res.Context1.At), firstRune(res.Context2.At); Confusable(r1, r2) {
+		fmt.Fprintf(&sb, "Position %d: %s is confusable with %s\n", position, p.describeConfusable(r1), p.describeConfusable(r2))
+	} else {
+		fmt.Fprintf(&sb, "Position %d: %s and %s are not confusable\n", position, p.describeConfusable(r1), p.describeConfusable(r2))
+	}
+
+	skeletonOpts := Options{CaseInsensitive: caseInsensitive, Skeleton: true}
+	if res := Compare(a, b, skeletonOpts); res.Equal() {
+		sb.WriteString("Strings only differ by confusable characters\n")
+	} else {
+		fmt.Fprintf(&sb, "Strings differ by more than confusable characters (see position %d)\n", res.Position1.Rune)
+	}
+	return sb.String()
+}
+
+// MaskedRegionsReport - list the regions of each string that masks replace
+// by a placeholder
+func (p Palette) MaskedRegionsReport(strs []string, masks []Mask) string {
+	var b strings.Builder
+	for n, str := range strs {
+		runes := []rune(str)
+		for _, region := range FindMasked(str, masks) {
+			fmt.Fprintf(&b, "Masked in string %d: %s at positions %d-%d: %s\n", n+1, region.Mask.Name,
+				region.Start+1, region.End, p.visible(runes[region.Start:region.End]))
+		}
+	}
+	return b.String()
+}
+
+// clip - return the visible form of a line, marking dropped text with "…"
+func (p Palette) clip(text string, clippedStart, clippedEnd bool) string {
+	text = p.visible([]rune(text))
+	if clippedStart {
+		text = "…" + text
+	}
+	if clippedEnd {
+		text += "…"
+	}
+	return text
+}
+
+// formatOffset - return the byte offset of a stream mismatch, showing both
+// offsets when case-insensitive matching has made them drift apart
+func formatOffset(m *StreamMismatch) string {
+	if m.Offset1 == m.Offset2 {
+		return fmt.Sprintf("%d", m.Offset1)
+	}
+	return fmt.Sprintf("%d/%d", m.Offset1, m.Offset2)
+}
+
+// writeFileContext - write the line before, the line containing and the line
+// after a mismatch at the given line of a file, with the differing rune
+// highlighted
+func (p Palette) writeFileContext(b *strings.Builder, name string, line int, ctx LineContext) {
+	fmt.Fprintf(b, "%s:\n", name)
+	if ctx.HasPrevious {
+		fmt.Fprintf(b, "  %d: %s\n", line-1, p.clip(ctx.Previous, ctx.PreviousClipped, false))
+	}
+
+	before := p.clip(ctx.Before, ctx.BeforeClipped, false)
+	if ctx.At == "" {
+		fmt.Fprintf(b, "  %d: %s[%s]\n", line, before, p.paint(ansiDiff, "END"))
+	} else {
+		fmt.Fprintf(b, "  %d: %s[%s]%s\n", line, before, p.paint(ansiDiff, p.visible([]rune(ctx.At))), p.clip(ctx.After, false, ctx.AfterClipped))
+	}
+
+	if ctx.HasNext {
+		fmt.Fprintf(b, "  %d: %s\n", line+1, p.clip(ctx.Next, false, ctx.NextClipped))
+	}
+}
+
+// FileReport - describe the first mismatch between two files, or nil when
+// they match, in detail with the lines around it when verbose
+func (p Palette) FileReport(name1, name2 string, m *StreamMismatch, verbose bool) string {
+	var b strings.Builder
+	switch {
+	case m == nil && verbose:
+		b.WriteString("Files match exactly\n")
+	case m == nil:
+		b.WriteString("0\n")
+	case verbose:
+		fmt.Fprintf(&b, "Files differ at line %d, column %d (byte offset %s)\n", m.Line, m.Column, formatOffset(m))
+		p.writeFileContext(&b, name1, m.Line, m.Context1)
+		p.writeFileContext(&b, name2, m.Line, m.Context2)
+	default:
+		fmt.Fprintf(&b, "line %d, column %d, byte offset %s\n", m.Line, m.Column, formatOffset(m))
+	}
+	return b.String()
+}
+
+// formatJSONValue - return a compact JSON rendering of a value for display
+func formatJSONValue(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// JSONReport - describe where and how two JSON documents differ, or that
+// they are equal when d is nil
+func (p Palette) JSONReport(d *JSONDifference) string {
+	if d == nil {
+		return "JSON documents are equal\n"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "JSON documents differ at %s: %s\n", p.paint(ansiDiff, d.Path), d.Reason)
+	for n, value := range []any{d.Value1, d.Value2} {
+		if d.Missing == n+1 {
+			fmt.Fprintf(&b, "Document %d: (missing)\n", n+1)
+		} else {
+			fmt.Fprintf(&b, "Document %d: %s\n", n+1, formatJSONValue(value))
+		}
+	}
+	return b.String()
+}
+
+// highlightToken - return up to ten runes either side of a token with the
+// token bracketed, or [END] at pos when the token is missing
+func (p Palette) highlightToken(runes []rune, token *Token, pos int) string {
+	start, end := pos, pos
+	text := "END"
+	if token != nil {
+		start, end = token.Start, token.End
+		text = p.visible(runes[start:end])
+	}
+	before := runes[max(0, start-10):start]
+	after := runes[end:min(len(runes), end+10)]
+	return p.visible(before) + "[" + p.paint(ansiDiff, text) + "]" + p.visible(after)
+}
+
+// NumericReport - describe the first differing token of two strings compared
+// by CompareTokens within tol, or that they match when d is nil
+func (p Palette) NumericReport(a, b string, d *TokenDifference, tol Tolerance) string {
+	if d == nil {
+		return "Strings match within tolerance\n"
+	}
+	var sb strings.Builder
+	position1, position2 := d.Positions(a, b)
+	fmt.Fprintf(&sb, "Strings differ at token %d (position %d)\n", d.Index+1, position1)
+	for n, token := range []*Token{d.Token1, d.Token2} {
+		switch {
+		case token == nil:
+			fmt.Fprintf(&sb, "Token %d: (missing)\n", n+1)
+		case token.Number:
+			fmt.Fprintf(&sb, "Token %d: \"%s\" (number)\n", n+1, p.visible([]rune(token.Text)))
+		default:
+			fmt.Fprintf(&sb, "Token %d: \"%s\"\n", n+1, p.visible([]rune(token.Text)))
+		}
+	}
+	if d.Token1 != nil && d.Token2 != nil && d.Token1.Number && d.Token2.Number {
+		fmt.Fprintf(&sb, "Numeric difference: %.6g (tolerance: absolute %g, relative %g)\n",
+			math.Abs(d.Token1.Value-d.Token2.Value), tol.Absolute, tol.Relative)
+	}
+	sb.WriteString("Difference:\n")
+	fmt.Fprintf(&sb, "String 1: %s\n", p.highlightToken([]rune(a), d.Token1, position1-1))
+	fmt.Fprintf(&sb, "String 2: %s\n", p.highlightToken([]rune(b), d.Token2, position2-1))
+	return sb.String()
+}
+
+// BatchReport - return a table with the result of every pair of a batch, the
+// position (in unit) of the first difference of each failing pair and its
+// name, followed by a summary and, when verbose, the detailed comparison of
+// every failing pair
+func (p Palette) BatchReport(pairs []BatchPair, results []Result, unit string, verbose bool) string {
+	var b strings.Builder
+	failed := CountMismatches(results)
+
+	// the columns are padded before painting, so escapes don't upset them
+	cells := make([]string, len(pairs))
+	numberWidth, positionWidth := len(strconv.Itoa(len(pairs))), len("POSITION")
+	for i := range pairs {
+		cells[i] = "-"
+		if !results[i].Equal() {
+			cells[i] = FormatPosition(results[i].Position1, unit)
+		}
+		positionWidth = max(positionWidth, len(cells[i]))
+	}
+	fmt.Fprintf(&b, "%-*s  RESULT  %-*s  NAME\n", numberWidth, "#", positionWidth, "POSITION")
+	for i, pair := range pairs {
+		result := p.paint(ansiAdded, "ok    ")
+		if !results[i].Equal() {
+			result = p.paint(ansiRemoved, "FAIL  ")
+		}
+		fmt.Fprintf(&b, "%-*d  %s  %-*s  %s\n", numberWidth, i+1, result, positionWidth, cells[i], p.visible([]rune(pair.Name)))
+	}
+	fmt.Fprintf(&b, "Summary: %d pair(s), %d passed, %d failed\n", len(pairs), len(pairs)-failed, failed)
+
+	if verbose {
+		for i, pair := range pairs {
+			if !results[i].Equal() {
+				fmt.Fprintf(&b, "\nPair %d (%s):\n", i+1, p.visible([]rune(pair.Name)))
+				b.WriteString(p.VerboseReport(results[i]))
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,318 @@
+package changecase
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseUnit tests validating the name of a position unit
+func TestParseUnit(t *testing.T) {
+	for _, name := range []string{UnitRune, UnitByte, UnitColumn, UnitLineCol} {
+		if got, err := ParseUnit(name); err != nil || got != name {
+			t.Errorf("Expected %q, got %q (%v)", name, got, err)
+		}
+	}
+	if _, err := ParseUnit("char"); err == nil {
+		t.Errorf("Expected an error for an unknown unit, got none")
+	}
+}
+
+// TestFormatPosition tests printing a position in each unit
+func TestFormatPosition(t *testing.T) {
+	pos := Locate("a\n漢x", 4)
+	tests := []struct {
+		unit     string
+		expected string
+	}{
+		{UnitRune, "4"},
+		{UnitByte, "6"},
+		{UnitColumn, "3"},
+		{UnitLineCol, "2:2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.unit, func(t *testing.T) {
+			if got := FormatPosition(pos, tt.unit); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestNewPalette tests choosing whether to color output
+func TestNewPalette(t *testing.T) {
+	if p, err := NewPalette("always"); err != nil || !p.Enabled {
+		t.Errorf("Expected colors with always, got %v (%v)", p.Enabled, err)
+	}
+	if p, err := NewPalette("never"); err != nil || p.Enabled {
+		t.Errorf("Expected no colors with never, got %v (%v)", p.Enabled, err)
+	}
+	t.Setenv("NO_COLOR", "1")
+	if p, err := NewPalette("auto"); err != nil || p.Enabled {
+		t.Errorf("Expected no colors with NO_COLOR set, got %v (%v)", p.Enabled, err)
+	}
+	if _, err := NewPalette("sometimes"); err == nil {
+		t.Errorf("Expected an error for an unknown color mode, got none")
+	}
+}
+
+// TestVisible tests escaping control and invisible runes
+func TestVisible(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		width    int
+	}{
+		{"plain", "a b", "a b", 3},
+		{"tab and newline", "a\tb\n", `a\tb\n`, 6},
+		{"control", "\x00\x7f", `\x00\x7f`, 8},
+		{"no-break space", "a\u00a0b", `a\u00a0b`, 8},
+		{"zero width space", "\u200b", `\u200b`, 6},
+		{"wide", "漢", "漢", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Palette{}).visible([]rune(tt.input)); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+			if got := visibleWidth([]rune(tt.input)); got != tt.width {
+				t.Errorf("Expected width %d, got %d", tt.width, got)
+			}
+		})
+	}
+
+	if got := (Palette{Enabled: true}).visible([]rune("a\tb")); got != "a\x1b[36m\\t\x1b[0mb" {
+		t.Errorf("Expected a colored escape, got %q", got)
+	}
+}
+
+// TestVerboseReport tests the detailed comparison of two strings
+func TestVerboseReport(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		colors   bool
+		expected string
+	}{
+		{"equal", "abc", "abc", false, "Strings match exactly\n"},
+		{"wide runes", "漢字x", "漢字y", false, "Strings differ at position 3\n" +
+			"String 1 position: rune 3, byte 7 (offset 6), column 5, line:col 1:3\n" +
+			"String 2 position: rune 3, byte 7 (offset 6), column 5, line:col 1:3\n" +
+			"Difference:\n" +
+			"String 1: 漢字[x]\n" +
+			"               ^\n" +
+			"String 2: 漢字[y]\n" +
+			"               ^\n" +
+			"Code points: U+0078 'x' vs U+0079 'y'\n"},
+		{"end of string", "ab", "abc", false, "Strings differ at position 3\n" +
+			"String 1 position: rune 3, byte 3 (offset 2), column 3, line:col 1:3\n" +
+			"String 2 position: rune 3, byte 3 (offset 2), column 3, line:col 1:3\n" +
+			"Difference:\n" +
+			"String 1: ab[END]\n" +
+			"             ^\n" +
+			"String 2: ab[c]\n" +
+			"             ^\n" +
+			"Code points: END vs U+0063 'c'\n"},
+		{"colors", "a\tb", "a\tc", true, "Strings differ at position 3\n" +
+			"String 1 position: rune 3, byte 3 (offset 2), column 9, line:col 1:3\n" +
+			"String 2 position: rune 3, byte 3 (offset 2), column 9, line:col 1:3\n" +
+			"Difference:\n" +
+			"String 1: a\x1b[36m\\t\x1b[0m[\x1b[1;31mb\x1b[0m]\n" +
+			"              \x1b[1;31m^\x1b[0m\n" +
+			"String 2: a\x1b[36m\\t\x1b[0m[\x1b[1;31mc\x1b[0m]\n" +
+			"              \x1b[1;31m^\x1b[0m\n" +
+			"Code points: U+0062 'b' vs U+0063 'c'\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Palette{Enabled: tt.colors}.VerboseReport(Compare(tt.a, tt.b, Options{}))
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestAllDifferencesReport tests listing every difference in diff's normal format
+func TestAllDifferencesReport(t *testing.T) {
+	expected := "Strings differ in 2 place(s)\n1c1\n< \"a\"\n> \"x\"\n3a4\n> \"d\"\nEdit distance: 3\nSimilarity: 57.14%\n"
+	if got := AllDifferencesReport("abc", "xbcd", Options{}, true); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+// TestManyReport tests listing the strings that deviate from the reference
+func TestManyReport(t *testing.T) {
+	strs := []string{"a", "b", "b"}
+	ref, results := CompareMany(strs, true, Options{})
+	tests := []struct {
+		name     string
+		verbose  bool
+		expected string
+	}{
+		{"positions", false, "string 1 differs at position 1\n"},
+		{"verbose", true, "Compared 3 strings against string 2 (majority)\n1 of 3 strings differ\n\n" +
+			"String 1 differs at position 1\n" +
+			"String 1 position: rune 1, byte 1 (offset 0), column 1, line:col 1:1\n" +
+			"Difference:\nString 2: [b]\n           ^\nString 1: [a]\n           ^\n" +
+			"Code points: U+0062 'b' vs U+0061 'a'\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Palette{}).ManyReport(len(strs), ref, results, true, tt.verbose, UnitRune); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	if got := (Palette{}).ManyReport(2, 0, []Result{{}, {}}, false, false, UnitRune); got != "0\n" {
+		t.Errorf("Expected %q, got %q", "0\n", got)
+	}
+}
+
+// TestMatchAndFuzzyReports tests describing pattern matches and similarity scores
+func TestMatchAndFuzzyReports(t *testing.T) {
+	tests := []struct {
+		name     string
+		got      string
+		expected string
+	}{
+		{"match", Palette{}.MatchReport("hello", MatchContains, 1, 3, true),
+			"Pattern matches (contains) at positions 2-3\nMatch: h[el]lo\n"},
+		{"empty match", Palette{}.MatchReport("hello", MatchGlob, 0, 0, true),
+			"Pattern matches (glob) with an empty match at position 1\nMatch: []hello\n"},
+		{"no match", Palette{}.MatchReport("hello", MatchPrefix, 0, 0, false), "Pattern does not match (prefix)\n"},
+		{"fuzzy", FuzzyReport(AlgoLevenshtein, 0.75, 1, 0.5, true),
+			"Algorithm: levenshtein\nDistance: 1\nSimilarity: 0.7500\nThreshold: 0.5000\nStrings match within the threshold\n"},
+		{"fuzzy without distance", FuzzyReport(AlgoJaro, 0.5, -1, 0.9, false),
+			"Algorithm: jaro\nSimilarity: 0.5000\nThreshold: 0.9000\nStrings differ beyond the threshold\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, tt.got)
+			}
+		})
+	}
+}
+
+// TestConfusablesReport tests explaining a look-alike difference
+func TestConfusablesReport(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected string
+	}{
+		{"equal", "pay", "pay", ""},
+		{"confusable", "p\u0430y", "pay", "Position 2: U+0430 CYRILLIC SMALL LETTER A is confusable with U+0061 LATIN SMALL LETTER A\n" +
+			"Strings only differ by confusable characters\n"},
+		{"not confusable", "pb", "pay", "Position 2: U+0062 LATIN SMALL LETTER B and U+0061 LATIN SMALL LETTER A are not confusable\n" +
+			"Strings differ by more than confusable characters (see position 2)\n"},
+		{"ends early", "pa", "pay", "Position 3: one string ends early, which is not a confusable difference\n" +
+			"Strings differ by more than confusable characters (see position 3)\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (Palette{}).ConfusablesReport(tt.a, tt.b, Compare(tt.a, tt.b, Options{}), false)
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestMaskedRegionsReport tests listing the masked regions of each string
+func TestMaskedRegionsReport(t *testing.T) {
+	mask, err := ParseMask("ipv4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Masked in string 2: ipv4 at positions 6-13: 10.0.0.1\n"
+	if got := (Palette{}).MaskedRegionsReport([]string{"host", "host 10.0.0.1"}, []Mask{mask}); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+// TestFileReport tests describing the first mismatch between two files
+func TestFileReport(t *testing.T) {
+	mismatch, err := CompareStreams(strings.NewReader("one\ntwo\nthree\n"), strings.NewReader("one\ntwo\nthr\u00e9e\n"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		mismatch *StreamMismatch
+		verbose  bool
+		expected string
+	}{
+		{"match", nil, false, "0\n"},
+		{"verbose match", nil, true, "Files match exactly\n"},
+		{"mismatch", mismatch, false, "line 3, column 4, byte offset 11\n"},
+		{"verbose mismatch", mismatch, true, "Files differ at line 3, column 4 (byte offset 11)\n" +
+			"a.txt:\n  2: two\n  3: thr[e]e\nb.txt:\n  2: two\n  3: thr[\u00e9]e\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Palette{}).FileReport("a.txt", "b.txt", tt.mismatch, tt.verbose); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestJSONAndNumericReports tests describing JSON and token differences
+func TestJSONAndNumericReports(t *testing.T) {
+	tol := Tolerance{Absolute: 0.1}
+	tests := []struct {
+		name     string
+		got      string
+		expected string
+	}{
+		{"json equal", Palette{}.JSONReport(nil), "JSON documents are equal\n"},
+		{"json missing", Palette{}.JSONReport(CompareJSON(map[string]any{"a": 1.0}, map[string]any{}, JSONOptions{})),
+			"JSON documents differ at .a: only in first document\nDocument 1: 1\nDocument 2: (missing)\n"},
+		{"numeric equal", Palette{}.NumericReport("x 1", "x 1.05", nil, tol), "Strings match within tolerance\n"},
+		{"numeric", Palette{}.NumericReport("x 1.0 y", "x 1.5", CompareTokens("x 1.0 y", "x 1.5", tol, false), tol),
+			"Strings differ at token 3 (position 3)\nToken 1: \"1.0\" (number)\nToken 2: \"1.5\" (number)\n" +
+				"Numeric difference: 0.5 (tolerance: absolute 0.1, relative 0)\n" +
+				"Difference:\nString 1: x [1.0] y\nString 2: x [1.5]\n"},
+		{"numeric missing token", Palette{}.NumericReport("x 1", "x", CompareTokens("x 1", "x", tol, false), tol),
+			"Strings differ at token 2 (position 2)\nToken 1: \" \"\nToken 2: (missing)\n" +
+				"Difference:\nString 1: x[ ]1\nString 2: x[END]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, tt.got)
+			}
+		})
+	}
+}
+
+// TestBatchReport tests the table of batch results
+func TestBatchReport(t *testing.T) {
+	pairs := []BatchPair{{"one", "a", "a"}, {"two\tx", "abc", "abd"}}
+	results := []Result{Compare("a", "a", Options{}), Compare("abc", "abd", Options{})}
+	expected := "#  RESULT  POSITION  NAME\n1  ok      -         one\n2  FAIL    3         two\\tx\nSummary: 2 pair(s), 1 passed, 1 failed\n"
+	if got := (Palette{}).BatchReport(pairs, results, UnitRune, false); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	// padding is added before the escapes, so colors don't move the columns
+	expected = "#  RESULT  POSITION  NAME\n1  \x1b[32mok    \x1b[0m  -         one\nSummary: 1 pair(s), 1 passed, 0 failed\n"
+	if got := (Palette{Enabled: true}).BatchReport(pairs[:1], results[:1], UnitRune, false); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	if got := (Palette{}).BatchReport(pairs, results, UnitRune, true); !strings.Contains(got, "\nPair 2 (two\\tx):\nStrings differ at position 3\n") {
+		t.Errorf("Expected the verbose comparison of pair 2, got %q", got)
+	}
+}
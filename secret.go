@@ -0,0 +1,59 @@
+package changecase
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ReadSecret - read a secret from a source that keeps it off the command
+// line: "env:NAME" (environment variable), "file:PATH" (file contents) or
+// "-" (the next line of stdin). A single trailing newline is removed from
+// file and stdin values.
+func ReadSecret(source string, stdin *bufio.Reader) ([]byte, error) {
+	var value []byte
+	switch {
+	case strings.HasPrefix(source, "env:"):
+		name := strings.TrimPrefix(source, "env:")
+		env, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", name)
+		}
+		return []byte(env), nil
+	case strings.HasPrefix(source, "file:"):
+		data, err := os.ReadFile(strings.TrimPrefix(source, "file:"))
+		if err != nil {
+			return nil, err
+		}
+		value = data
+	case source == "-":
+		line, err := stdin.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return nil, errors.New("could not read a line from stdin")
+		}
+		value = line
+	default:
+		return nil, errors.New("secrets must come from env:NAME, file:PATH or - (stdin), not the command line")
+	}
+
+	if n := len(value); n > 0 && value[n-1] == '\n' {
+		value = value[:n-1]
+		if n > 1 && value[n-2] == '\r' {
+			value = value[:n-2]
+		}
+	}
+	return value, nil
+}
+
+// SecretsEqual - compare two secrets in constant time. Both are hashed first
+// so that the comparison does not stop early when their lengths differ.
+func SecretsEqual(secret1, secret2 []byte) bool {
+	hash1 := sha256.Sum256(secret1)
+	hash2 := sha256.Sum256(secret2)
+	return subtle.ConstantTimeCompare(hash1[:], hash2[:]) == 1
+}
@@ -0,0 +1,73 @@
+package changecase
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestReadSecret tests each source a secret can be read from
+func TestReadSecret(t *testing.T) {
+	t.Setenv("CHANGECASE_TEST_SECRET", "from env\n")
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("from file\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		source   string
+		stdin    string
+		expected string
+		err      string
+	}{
+		{"env kept as is", "env:CHANGECASE_TEST_SECRET", "", "from env\n", ""},
+		{"env unset", "env:CHANGECASE_TEST_UNSET", "", "", "environment variable CHANGECASE_TEST_UNSET is not set"},
+		{"file newline removed", "file:" + path, "", "from file", ""},
+		{"missing file", "file:" + path + ".missing", "", "", "open "},
+		{"stdin line", "-", "first\nsecond\n", "first", ""},
+		{"stdin without newline", "-", "only", "only", ""},
+		{"empty stdin", "-", "", "", "could not read a line from stdin"},
+		{"command line", "hunter2", "", "", "secrets must come from"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadSecret(tt.source, bufio.NewReader(strings.NewReader(tt.stdin)))
+			if tt.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+					t.Errorf("Expected an error starting with %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestSecretsEqual tests the constant time comparison of secrets
+func TestSecretsEqual(t *testing.T) {
+	tests := []struct {
+		secret1, secret2 string
+		expected         bool
+	}{
+		{"s3cret", "s3cret", true},
+		{"", "", true},
+		{"s3cret", "s3creT", false},
+		{"s3cret", "s3cret!", false},
+		{"s3cret", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := SecretsEqual([]byte(tt.secret1), []byte(tt.secret2)); got != tt.expected {
+			t.Errorf("SecretsEqual(%q, %q): expected %v, got %v", tt.secret1, tt.secret2, tt.expected, got)
+		}
+	}
+}
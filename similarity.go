@@ -0,0 +1,207 @@
+package changecase
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Algorithm - a fuzzy string similarity algorithm
+type Algorithm string
+
+const (
+	AlgoLevenshtein Algorithm = "levenshtein"
+	AlgoDamerau     Algorithm = "damerau"
+	AlgoJaro        Algorithm = "jaro"
+	AlgoJaroWinkler Algorithm = "jaro-winkler"
+)
+
+// Algorithms - every supported similarity algorithm, in display order
+var Algorithms = []Algorithm{AlgoLevenshtein, AlgoDamerau, AlgoJaro, AlgoJaroWinkler}
+
+// ParseAlgorithm - return the Algorithm with the given (case-insensitive) name
+func ParseAlgorithm(name string) (Algorithm, error) {
+	for _, algo := range Algorithms {
+		if strings.EqualFold(name, string(algo)) {
+			return algo, nil
+		}
+	}
+	return "", fmt.Errorf("unknown algorithm %q", name)
+}
+
+// IsEditDistance - report whether the algorithm is based on an edit distance
+func (algo Algorithm) IsEditDistance() bool {
+	return algo == AlgoLevenshtein || algo == AlgoDamerau
+}
+
+// Similarity - return a score between 0 (nothing in common) and 1 (identical)
+// for a and b using the given algorithm. Edit distances are normalized by the
+// rune length of the longer string.
+func Similarity(a, b string, algo Algorithm) float64 {
+	score, _ := Score(a, b, algo)
+	return score
+}
+
+// Score - return the Similarity of a and b along with, for the edit distance
+// algorithms, the distance it was derived from (-1 for the others)
+func Score(a, b string, algo Algorithm) (float64, int) {
+	switch algo {
+	case AlgoJaro:
+		return Jaro(a, b), -1
+	case AlgoJaroWinkler:
+		return JaroWinkler(a, b), -1
+	}
+
+	var distance int
+	if algo == AlgoDamerau {
+		distance = DamerauLevenshtein(a, b)
+	} else {
+		distance = Levenshtein(a, b)
+	}
+	longest := max(len([]rune(a)), len([]rune(b)))
+	if longest == 0 {
+		return 1, distance
+	}
+	return float64(longest-distance) / float64(longest), distance
+}
+
+// FuzzyScore - return the Score of a and b once prepared with the case,
+// whitespace, mask and skeleton options
+func FuzzyScore(a, b string, algo Algorithm, opts Options) (float64, int) {
+	runes1, _ := Prepare(a, opts)
+	runes2, _ := Prepare(b, opts)
+	return Score(string(runes1), string(runes2), algo)
+}
+
+// Levenshtein - return the minimum number of single-rune insertions,
+// deletions and substitutions needed to turn a into b
+func Levenshtein(a, b string) int {
+	r1, r2 := []rune(a), []rune(b)
+	prev := make([]int, len(r2)+1)
+	curr := make([]int, len(r2)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(r1); i++ {
+		curr[0] = i
+		for j := 1; j <= len(r2); j++ {
+			cost := 1
+			if r1[i-1] == r2[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(r2)]
+}
+
+// DamerauLevenshtein - like Levenshtein, but a transposition of two adjacent
+// runes also counts as a single edit (unrestricted Damerau-Levenshtein)
+func DamerauLevenshtein(a, b string) int {
+	r1, r2 := []rune(a), []rune(b)
+	la, lb := len(r1), len(r2)
+	inf := la + lb
+
+	// d is offset by one row and column to hold the "infinity" border
+	d := make([][]int, la+2)
+	for i := range d {
+		d[i] = make([]int, lb+2)
+	}
+	d[0][0] = inf
+	for i := 0; i <= la; i++ {
+		d[i+1][0] = inf
+		d[i+1][1] = i
+	}
+	for j := 0; j <= lb; j++ {
+		d[0][j+1] = inf
+		d[1][j+1] = j
+	}
+
+	// lastRow holds the last row in which each rune of a was seen
+	lastRow := make(map[rune]int)
+	for i := 1; i <= la; i++ {
+		lastCol := 0
+		for j := 1; j <= lb; j++ {
+			i1 := lastRow[r2[j-1]]
+			j1 := lastCol
+			cost := 1
+			if r1[i-1] == r2[j-1] {
+				cost = 0
+				lastCol = j
+			}
+			d[i+1][j+1] = min(
+				d[i][j]+cost,
+				d[i+1][j]+1,
+				d[i][j+1]+1,
+				d[i1][j1]+(i-i1-1)+1+(j-j1-1),
+			)
+		}
+		lastRow[r1[i-1]] = i
+	}
+	return d[la+1][lb+1]
+}
+
+// Jaro - return the Jaro similarity of a and b
+func Jaro(a, b string) float64 {
+	r1, r2 := []rune(a), []rune(b)
+	if len(r1) == 0 && len(r2) == 0 {
+		return 1
+	}
+	if len(r1) == 0 || len(r2) == 0 {
+		return 0
+	}
+
+	window := max(max(len(r1), len(r2))/2-1, 0)
+	matched1 := make([]bool, len(r1))
+	matched2 := make([]bool, len(r2))
+	matches := 0
+	for i := range r1 {
+		lo := max(0, i-window)
+		hi := min(len(r2), i+window+1)
+		for j := lo; j < hi; j++ {
+			if !matched2[j] && r1[i] == r2[j] {
+				matched1[i] = true
+				matched2[j] = true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	// count matched runes that appear in a different order
+	transpositions := 0
+	j := 0
+	for i := range r1 {
+		if !matched1[i] {
+			continue
+		}
+		for !matched2[j] {
+			j++
+		}
+		if r1[i] != r2[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	return (m/float64(len(r1)) + m/float64(len(r2)) + (m-float64(transpositions)/2)/m) / 3
+}
+
+// JaroWinkler - return the Jaro similarity of a and b, boosted for a common
+// prefix of up to four runes when the Jaro score exceeds 0.7
+func JaroWinkler(a, b string) float64 {
+	score := Jaro(a, b)
+	if score <= 0.7 {
+		return score
+	}
+	r1, r2 := []rune(a), []rune(b)
+	prefix := 0
+	for prefix < min(4, len(r1), len(r2)) && r1[prefix] == r2[prefix] {
+		prefix++
+	}
+	return score + float64(prefix)*0.1*(1-score)
+}
@@ -0,0 +1,107 @@
+package changecase
+
+import (
+	"math"
+	"testing"
+)
+
+// TestEditDistances tests Levenshtein and Damerau-Levenshtein distances
+func TestEditDistances(t *testing.T) {
+	tests := []struct {
+		name        string
+		str1        string
+		str2        string
+		levenshtein int
+		damerau     int
+	}{
+		{"identical", "hello", "hello", 0, 0},
+		{"empty strings", "", "", 0, 0},
+		{"one empty", "abc", "", 3, 3},
+		{"kitten sitting", "kitten", "sitting", 3, 3},
+		{"transposition", "ab", "ba", 2, 1},
+		{"non-adjacent transposition", "ca", "abc", 3, 2},
+		{"insertion", "Jon Smith", "John Smith", 1, 1},
+		{"multi-byte unicode", "日本語", "日本話", 1, 1},
+		{"emoji transposition", "👋😊", "😊👋", 2, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Levenshtein(tt.str1, tt.str2); got != tt.levenshtein {
+				t.Errorf("Levenshtein: expected %d, got %d", tt.levenshtein, got)
+			}
+			if got := DamerauLevenshtein(tt.str1, tt.str2); got != tt.damerau {
+				t.Errorf("DamerauLevenshtein: expected %d, got %d", tt.damerau, got)
+			}
+		})
+	}
+}
+
+// TestSimilarity tests the normalized similarity scores of every algorithm
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name     string
+		str1     string
+		str2     string
+		algo     Algorithm
+		expected float64
+	}{
+		{"levenshtein", "Jon Smith", "John Smith", AlgoLevenshtein, 0.9},
+		{"damerau", "ab", "ba", AlgoDamerau, 0.5},
+		{"levenshtein empty", "", "", AlgoLevenshtein, 1},
+		{"jaro", "MARTHA", "MARHTA", AlgoJaro, 0.9444},
+		{"jaro-winkler", "MARTHA", "MARHTA", AlgoJaroWinkler, 0.9611},
+		{"jaro-winkler dwayne", "DWAYNE", "DUANE", AlgoJaroWinkler, 0.84},
+		{"jaro no match", "abc", "xyz", AlgoJaro, 0},
+		{"empty strings", "", "", AlgoJaroWinkler, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Similarity(tt.str1, tt.str2, tt.algo)
+			if math.Abs(got-tt.expected) > 0.0001 {
+				t.Errorf("Expected %.4f, got %.4f", tt.expected, got)
+			}
+			score, distance := Score(tt.str1, tt.str2, tt.algo)
+			if score != got || (distance >= 0) != tt.algo.IsEditDistance() {
+				t.Errorf("Score: expected %.4f with a distance only for edit distances, got %.4f and %d", got, score, distance)
+			}
+		})
+	}
+}
+
+// TestFuzzyScore tests that the comparison options apply before scoring
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		str1     string
+		str2     string
+		opts     Options
+		score    float64
+		distance int
+	}{
+		{"plain", "Jon Smith", "John Smith", Options{}, 0.9, 1},
+		{"case counts", "JOHN", "john", Options{}, 0, 4},
+		{"case insensitive", "JOHN", "john", Options{CaseInsensitive: true}, 1, 0},
+		{"whitespace ignored", "John  Smith ", "John Smith", Options{Whitespace: WhitespaceOptions{Collapse: true, IgnoreTrailing: true}}, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, distance := FuzzyScore(tt.str1, tt.str2, AlgoLevenshtein, tt.opts)
+			if math.Abs(score-tt.score) > 0.0001 || distance != tt.distance {
+				t.Errorf("Expected %.4f (distance %d), got %.4f (distance %d)", tt.score, tt.distance, score, distance)
+			}
+		})
+	}
+}
+
+// TestParseAlgorithm tests algorithm name lookup
+func TestParseAlgorithm(t *testing.T) {
+	if algo, err := ParseAlgorithm("Jaro-Winkler"); err != nil || algo != AlgoJaroWinkler {
+		t.Errorf("Expected %s, got %s (%v)", AlgoJaroWinkler, algo, err)
+	}
+	if _, err := ParseAlgorithm("soundex"); err == nil {
+		t.Errorf("Expected an error for an unknown algorithm, got none")
+	}
+}
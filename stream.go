@@ -0,0 +1,237 @@
+package changecase
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// StreamContextRunes - the most runes of each line kept to show around a
+// mismatch found by CompareStreams; longer lines are clipped so memory use
+// stays constant
+const StreamContextRunes = 40
+
+// StreamMismatch - the first difference between two streams
+type StreamMismatch struct {
+	Line     int         // 1-based line number
+	Column   int         // 1-based rune column within the line
+	Offset1  int64       // 0-based byte offset in the first stream
+	Offset2  int64       // 0-based byte offset in the second stream
+	Context1 LineContext // the lines around the mismatch in the first stream
+	Context2 LineContext // the lines around the mismatch in the second stream
+}
+
+// LineContext - the line holding a stream mismatch and the lines either side
+// of it, each clipped to StreamContextRunes runes and without line endings
+type LineContext struct {
+	Previous        string // the line before the mismatch, if HasPrevious
+	HasPrevious     bool
+	PreviousClipped bool   // the start of Previous was dropped
+	Before          string // the text of the line before the mismatch
+	BeforeClipped   bool   // the start of Before was dropped
+	At              string // the differing rune, or "" at the end of the line or stream
+	After           string // the text of the line after At
+	AfterClipped    bool   // the end of After was dropped
+	Next            string // the line after the mismatch, if HasNext
+	HasNext         bool
+	NextClipped     bool // the end of Next was dropped
+}
+
+// CompareStreams - read two UTF-8 streams rune by rune and return their
+// first mismatch, or nil if they are identical. Only the end of the previous
+// and current line of each stream is held, so streams of any size, with
+// lines of any length, are compared in constant memory. Invalid bytes are
+// compared as bytes, so two different invalid bytes do not match.
+func CompareStreams(r1, r2 io.Reader, caseInsensitive bool) (*StreamMismatch, error) {
+	c1 := &streamCursor{reader: bufio.NewReader(r1)}
+	c2 := &streamCursor{reader: bufio.NewReader(r2)}
+	line, column := 1, 1
+	for {
+		rune1, size1, raw1, err1 := c1.peekRune()
+		if err1 != nil && err1 != io.EOF {
+			return nil, err1
+		}
+		rune2, size2, raw2, err2 := c2.peekRune()
+		if err2 != nil && err2 != io.EOF {
+			return nil, err2
+		}
+		if err1 == io.EOF && err2 == io.EOF {
+			return nil, nil
+		}
+
+		same := err1 == nil && err2 == nil && raw1 == raw2
+		if same && rune1 != rune2 {
+			same = caseInsensitive && FoldRune(rune1) == FoldRune(rune2)
+		}
+		if !same {
+			return &StreamMismatch{Line: line, Column: column, Offset1: c1.offset, Offset2: c2.offset,
+				Context1: c1.context(), Context2: c2.context()}, nil
+		}
+
+		c1.advance(rune1, size1)
+		c2.advance(rune2, size2)
+		if rune1 == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+}
+
+// streamCursor - reads runes from a stream while remembering the end of the
+// previous and current line, so that a mismatch can be shown with its
+// surrounding lines without holding the whole stream (or line) in memory
+type streamCursor struct {
+	reader      *bufio.Reader
+	prevLine    string
+	prevClipped bool // the start of prevLine was dropped
+	hasPrev     bool
+	line        []rune // up to 2*StreamContextRunes runes, the last of which are kept
+	lineClipped bool   // the start of the current line was dropped
+	offset      int64
+}
+
+// peekRune - return the next rune and its size in bytes without consuming
+// it. Invalid UTF-8 bytes are returned as utf8.RuneError with size 1, and raw
+// holds the byte itself so that two different invalid bytes do not compare
+// as equal.
+func (c *streamCursor) peekRune() (r rune, size int, raw byte, err error) {
+	buf, err := c.reader.Peek(utf8.UTFMax)
+	if len(buf) == 0 {
+		return 0, 0, 0, err
+	}
+	r, size = utf8.DecodeRune(buf)
+	if r == utf8.RuneError && size == 1 {
+		raw = buf[0]
+	}
+	return r, size, raw, nil
+}
+
+// advance - consume a rune that matched in both streams
+func (c *streamCursor) advance(r rune, size int) {
+	c.reader.Discard(size)
+	c.offset += int64(size)
+	if r == '\n' {
+		c.prevLine = strings.TrimSuffix(string(c.lineTail()), "\r")
+		c.prevClipped = c.lineClipped
+		c.hasPrev = true
+		c.line, c.lineClipped = c.line[:0], false
+		return
+	}
+	// keep the buffer bounded, moving its newest runes to the front when full
+	if len(c.line) == 2*StreamContextRunes {
+		c.line = append(c.line[:0], c.line[StreamContextRunes:]...)
+		c.lineClipped = true
+	}
+	c.line = append(c.line, r)
+}
+
+// lineTail - return the last StreamContextRunes runes of the current line
+func (c *streamCursor) lineTail() []rune {
+	if len(c.line) > StreamContextRunes {
+		c.lineClipped = true
+		return c.line[len(c.line)-StreamContextRunes:]
+	}
+	return c.line
+}
+
+// readLine - read up to the next newline, returning at most
+// StreamContextRunes runes of the text without the line ending, whether the
+// rest of the line was skipped and whether anything was read before EOF
+func (c *streamCursor) readLine() ([]rune, bool, bool) {
+	var runes []rune
+	clipped, read := false, false
+	for {
+		r, _, err := c.reader.ReadRune()
+		if err != nil {
+			break
+		}
+		read = true
+		if r == '\n' {
+			break
+		}
+		if len(runes) < StreamContextRunes {
+			runes = append(runes, r)
+		} else {
+			clipped = true
+		}
+	}
+	if !clipped && len(runes) > 0 && runes[len(runes)-1] == '\r' {
+		runes = runes[:len(runes)-1]
+	}
+	return runes, clipped, read
+}
+
+// context - read the rest of the current line and the next one, consuming
+// them, and return them with the lines held so far
+func (c *streamCursor) context() LineContext {
+	ctx := LineContext{Previous: c.prevLine, HasPrevious: c.hasPrev, PreviousClipped: c.prevClipped}
+	ctx.Before = string(c.lineTail())
+	ctx.BeforeClipped = c.lineClipped
+
+	if runes, clipped, ok := c.readLine(); ok && len(runes) > 0 {
+		ctx.At, ctx.After, ctx.AfterClipped = string(runes[:1]), string(runes[1:]), clipped
+	}
+	if next, clipped, ok := c.readLine(); ok {
+		ctx.Next, ctx.HasNext, ctx.NextClipped = string(next), true, clipped
+	}
+	return ctx
+}
+
+// ReadLine - read one line of any length from reader, dropping the line
+// ending as bufio.ScanLines does, and report false at the end of the input
+func ReadLine(reader *bufio.Reader) (string, bool, error) {
+	line, err := reader.ReadString('\n')
+	if err == io.EOF {
+		if line == "" {
+			return "", false, nil
+		}
+		err = nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), true, nil
+}
+
+// LineRuneReader - returns the runes of a single line of a bufio.Reader, one
+// at a time, and then io.EOF. The line ending is consumed but not returned,
+// with a CR dropped before LF or at the end of the input as bufio.ScanLines
+// does. Together with CompareRuneReaders, it compares a line as it is read.
+type LineRuneReader struct {
+	reader *bufio.Reader
+	done   bool
+}
+
+// NewLineRuneReader - return a LineRuneReader of the next line of reader
+func NewLineRuneReader(reader *bufio.Reader) *LineRuneReader {
+	return &LineRuneReader{reader: reader}
+}
+
+// ReadRune - return the next rune of the line
+func (l *LineRuneReader) ReadRune() (rune, int, error) {
+	if l.done {
+		return 0, 0, io.EOF
+	}
+	r, size, err := l.reader.ReadRune()
+	if err != nil || r == '\n' {
+		l.done = true
+		if err == nil {
+			err = io.EOF
+		}
+		return 0, 0, err
+	}
+	if r == '\r' {
+		next, _, err := l.reader.ReadRune()
+		switch {
+		case err == io.EOF || (err == nil && next == '\n'):
+			l.done = true
+			return 0, 0, io.EOF
+		case err == nil:
+			_ = l.reader.UnreadRune()
+		}
+	}
+	return r, size, nil
+}
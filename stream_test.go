@@ -0,0 +1,112 @@
+package changecase
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+)
+
+// TestCompareStreams tests the position, offsets and context of the first
+// mismatch between two streams
+func TestCompareStreams(t *testing.T) {
+	long := strings.Repeat("x", 3*StreamContextRunes)
+	tests := []struct {
+		name            string
+		str1            string
+		str2            string
+		caseInsensitive bool
+		line, column    int
+		offset1         int64
+		context1        LineContext
+	}{
+		{"identical", "abc\ndef\n", "abc\ndef\n", false, 0, 0, 0, LineContext{}},
+		{"first rune", "abc", "xbc", false, 1, 1, 0, LineContext{At: "a", After: "bc"}},
+		{"second line", "abc\ndef\nghi\n", "abc\ndXf\nghi\n", false, 2, 2, 5,
+			LineContext{Previous: "abc", HasPrevious: true, Before: "d", At: "e", After: "f", Next: "ghi", HasNext: true}},
+		{"crlf dropped from context", "ab\r\ncd\r\n", "ab\r\ncX\r\n", false, 2, 2, 5,
+			LineContext{Previous: "ab", HasPrevious: true, Before: "c", At: "d"}},
+		{"first ends early", "abc", "abcd", false, 1, 4, 3, LineContext{Before: "abc"}},
+		{"multi-byte offset", "日本語", "日本話", false, 1, 3, 6, LineContext{Before: "日本", At: "語"}},
+		{"case insensitive", "ΣΊΣΥΦΟΣ", "σίσυφοσ", true, 0, 0, 0, LineContext{}},
+		{"folded one rune at a time", "Straße", "STRASSE", true, 1, 5, 4, LineContext{Before: "Stra", At: "ß", After: "e"}},
+		{"different invalid bytes", "a\xffb", "a\xfeb", false, 1, 2, 1, LineContext{Before: "a", At: "\uFFFD", After: "b"}},
+		{"long line clipped", long + "a" + long, long + "b" + long, false, 1, len(long) + 1, int64(len(long)),
+			LineContext{Before: long[:StreamContextRunes], BeforeClipped: true, At: "a", After: long[:StreamContextRunes-1], AfterClipped: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := CompareStreams(strings.NewReader(tt.str1), strings.NewReader(tt.str2), tt.caseInsensitive)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tt.line == 0 {
+				if m != nil {
+					t.Errorf("Expected no mismatch, got %+v", *m)
+				}
+				return
+			}
+			if m == nil {
+				t.Fatal("Expected a mismatch, got none")
+			}
+			if m.Line != tt.line || m.Column != tt.column || m.Offset1 != tt.offset1 {
+				t.Errorf("Expected line %d, column %d, offset %d, got %d, %d, %d",
+					tt.line, tt.column, tt.offset1, m.Line, m.Column, m.Offset1)
+			}
+			if m.Context1 != tt.context1 {
+				t.Errorf("Expected context %+v, got %+v", tt.context1, m.Context1)
+			}
+		})
+	}
+}
+
+// TestCompareStreamsReadError tests that a read error is returned rather
+// than reported as a mismatch
+func TestCompareStreamsReadError(t *testing.T) {
+	r := io.MultiReader(strings.NewReader("abc"), errReader{})
+	if _, err := CompareStreams(r, strings.NewReader("abcdef"), false); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected %v, got %v", io.ErrUnexpectedEOF, err)
+	}
+}
+
+// errReader - a reader that always fails
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, io.ErrUnexpectedEOF
+}
+
+// TestLineRuneReader tests reading lines rune by rune with the line endings
+// of bufio.ScanLines
+func TestLineRuneReader(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("ab\r\nc\rd\ne\r"))
+	for _, expected := range []string{"ab", "c\rd", "e"} {
+		var sb strings.Builder
+		lr := NewLineRuneReader(reader)
+		for {
+			r, _, err := lr.ReadRune()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			sb.WriteRune(r)
+		}
+		if sb.String() != expected {
+			t.Errorf("Expected %q, got %q", expected, sb.String())
+		}
+	}
+
+	reader = bufio.NewReader(strings.NewReader("one\r\ntwo"))
+	for _, expected := range []string{"one", "two"} {
+		line, ok, err := ReadLine(reader)
+		if !ok || err != nil || line != expected {
+			t.Errorf("Expected %q, got %q (%v, %v)", expected, line, ok, err)
+		}
+	}
+	if _, ok, err := ReadLine(reader); ok || err != nil {
+		t.Errorf("Expected the end of the input, got %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,79 @@
+package changecase
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TrimSide - which ends of a string Trim removes characters from
+type TrimSide int
+
+const (
+	TrimBoth  TrimSide = iota // both ends
+	TrimLeft                  // the start only
+	TrimRight                 // the end only
+)
+
+// TrimOptions - what Trim removes
+type TrimOptions struct {
+	Side    TrimSide
+	Cutset  string // remove these characters instead of whitespace
+	PerLine bool   // trim every line, keeping its line ending, rather than the whole string
+}
+
+// IsTrimSpace - report whether Trim removes r as whitespace: every rune with
+// the Unicode White_Space property, such as NBSP (U+00A0) and the
+// ideographic space (U+3000), plus the invisible zero width space (U+200B),
+// word joiner (U+2060) and zero width no-break space or byte order mark
+// (U+FEFF), which are not White_Space but are just as unwanted at the ends
+// of a string
+func IsTrimSpace(r rune) bool {
+	switch r {
+	case '\u200b', '\u2060', '\ufeff':
+		return true
+	}
+	return unicode.IsSpace(r)
+}
+
+// Trim - remove whitespace, or the runes of opts.Cutset, from one or both
+// ends of s, or of every line of s
+func Trim(s string, opts TrimOptions) string {
+	if !opts.PerLine {
+		return trimString(s, opts)
+	}
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for len(s) > 0 {
+		line, rest, found := strings.Cut(s, "\n")
+		s = rest
+		sb.WriteString(TrimLine(line, opts))
+		if found {
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+// TrimLine - trim a single line that may end with a carriage return, which
+// is kept so that CRLF line endings survive
+func TrimLine(line string, opts TrimOptions) string {
+	if cr := strings.HasSuffix(line, "\r"); cr {
+		return trimString(line[:len(line)-1], opts) + "\r"
+	}
+	return trimString(line, opts)
+}
+
+// trimString - trim s as a whole
+func trimString(s string, opts TrimOptions) string {
+	cut := IsTrimSpace
+	if opts.Cutset != "" {
+		cut = func(r rune) bool { return strings.ContainsRune(opts.Cutset, r) }
+	}
+	switch opts.Side {
+	case TrimLeft:
+		return strings.TrimLeftFunc(s, cut)
+	case TrimRight:
+		return strings.TrimRightFunc(s, cut)
+	}
+	return strings.TrimFunc(s, cut)
+}
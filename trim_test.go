@@ -0,0 +1,37 @@
+package changecase
+
+import "testing"
+
+// TestTrim tests each side, cutsets and per-line trimming
+func TestTrim(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     TrimOptions
+		expected string
+	}{
+		{"ascii", "  hello \t\n", TrimOptions{}, "hello"},
+		{"nbsp", "\u00a0hello\u00a0", TrimOptions{}, "hello"},
+		{"ideographic space", "\u3000日本\u3000", TrimOptions{}, "日本"},
+		{"byte order mark", "\ufeffhello\u200b", TrimOptions{}, "hello"},
+		{"inner spaces kept", " a\u00a0b ", TrimOptions{}, "a\u00a0b"},
+		{"left", "  a  ", TrimOptions{Side: TrimLeft}, "a  "},
+		{"right", "  a  ", TrimOptions{Side: TrimRight}, "  a"},
+		{"cutset", "--==a=b==--", TrimOptions{Cutset: "-="}, "a=b"},
+		{"cutset keeps spaces", " -a- ", TrimOptions{Cutset: "-"}, " -a- "},
+		{"unicode cutset", "«a»", TrimOptions{Cutset: "«»"}, "a"},
+		{"whole input", "  a  \n  b  \n", TrimOptions{}, "a  \n  b"},
+		{"per line", "  a  \n  b  \n", TrimOptions{PerLine: true}, "a\nb\n"},
+		{"per line crlf", " a \r\n b \r\n", TrimOptions{PerLine: true}, "a\r\nb\r\n"},
+		{"per line right", "a  \n\n  b", TrimOptions{PerLine: true, Side: TrimRight}, "a\n\n  b"},
+		{"empty", "", TrimOptions{PerLine: true}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Trim(tt.input, tt.opts); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package changecase
+
+import "unicode"
+
+// WhitespaceOptions - which whitespace and line ending differences to ignore
+// when comparing strings. Line feeds are treated as line breaks rather than
+// whitespace, so lines are never merged together.
+type WhitespaceOptions struct {
+	IgnoreTrailing bool // drop whitespace at the end of every line
+	Collapse       bool // treat each run of whitespace as a single space
+	IgnoreAll      bool // drop all whitespace
+	NormalizeEOL   bool // convert CRLF and lone CR line endings to LF
+}
+
+// Enabled - report whether any whitespace option is set
+func (opts WhitespaceOptions) Enabled() bool {
+	return opts.IgnoreTrailing || opts.Collapse || opts.IgnoreAll || opts.NormalizeEOL
+}
+
+// NormalizeWhitespace - apply opts to s and return the normalized runes along
+// with a map from each normalized rune to the 0-based index of the rune in s
+// it came from. The map holds one extra entry, len([]rune(s)), for the end of
+// the string so that a mismatch past the last rune can also be mapped back.
+func NormalizeWhitespace(s string, opts WhitespaceOptions) ([]rune, []int) {
+	runes := []rune(s)
+	out := make([]rune, 0, len(runes))
+	origin := make([]int, 0, len(runes)+1)
+
+	// pending holds the whitespace seen since the last non-whitespace rune;
+	// it is only emitted once we know it is not trailing
+	var pending []int
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		switch {
+		case opts.IgnoreAll:
+		case opts.Collapse:
+			out = append(out, ' ')
+			origin = append(origin, pending[0])
+		default:
+			for _, i := range pending {
+				out = append(out, runes[i])
+				origin = append(origin, i)
+			}
+		}
+		pending = pending[:0]
+	}
+
+	for i := 0; i < len(runes); i++ {
+		start, r := i, runes[i]
+		if opts.NormalizeEOL && r == '\r' {
+			// "\r\n" and a lone "\r" both become a single "\n"
+			if i+1 < len(runes) && runes[i+1] == '\n' {
+				i++
+			}
+			r = '\n'
+		}
+
+		switch {
+		case r == '\n':
+			if !opts.IgnoreTrailing {
+				flush()
+			}
+			pending = pending[:0]
+			out = append(out, '\n')
+			origin = append(origin, start)
+		case unicode.IsSpace(r):
+			pending = append(pending, i)
+		default:
+			flush()
+			out = append(out, r)
+			origin = append(origin, i)
+		}
+	}
+	if !opts.IgnoreTrailing {
+		flush()
+	}
+
+	origin = append(origin, len(runes))
+	return out, origin
+}
@@ -0,0 +1,39 @@
+package changecase
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestNormalizeWhitespace tests each whitespace option and the map back to the original runes
+func TestNormalizeWhitespace(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     WhitespaceOptions
+		expected string
+		origin   []int
+	}{
+		{"no options", "a b", WhitespaceOptions{}, "a b", []int{0, 1, 2, 3}},
+		{"trailing", "a \t\nb  ", WhitespaceOptions{IgnoreTrailing: true}, "a\nb", []int{0, 3, 4, 7}},
+		{"collapse", "a \t b", WhitespaceOptions{Collapse: true}, "a b", []int{0, 1, 4, 5}},
+		{"collapse keeps one space", "a b", WhitespaceOptions{Collapse: true}, "a b", []int{0, 1, 2, 3}},
+		{"ignore all", " a b ", WhitespaceOptions{IgnoreAll: true}, "ab", []int{1, 3, 5}},
+		{"crlf", "a\r\nb", WhitespaceOptions{NormalizeEOL: true}, "a\nb", []int{0, 1, 3, 4}},
+		{"lone cr", "a\rb", WhitespaceOptions{NormalizeEOL: true}, "a\nb", []int{0, 1, 2, 3}},
+		{"trailing strips cr", "a\r\nb", WhitespaceOptions{IgnoreTrailing: true}, "a\nb", []int{0, 2, 3, 4}},
+		{"unicode spaces", "a 　b", WhitespaceOptions{Collapse: true}, "a b", []int{0, 1, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runes, origin := NormalizeWhitespace(tt.input, tt.opts)
+			if string(runes) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, string(runes))
+			}
+			if !reflect.DeepEqual(origin, tt.origin) {
+				t.Errorf("Expected origin %v, got %v", tt.origin, origin)
+			}
+		})
+	}
+}
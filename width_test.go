@@ -0,0 +1,30 @@
+package changecase
+
+import "testing"
+
+// TestStringWidth tests display widths of narrow, wide and zero-width runes
+func TestStringWidth(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		{"ascii", "hello", 5},
+		{"empty", "", 0},
+		{"latin accents", "café", 4},
+		{"combining mark", "cafe\u0301", 4},
+		{"cjk", "日本語", 6},
+		{"fullwidth", "ＡＢ", 4},
+		{"emoji", "👋😊", 4},
+		{"zero width space", "a\u200bb", 2},
+		{"control characters", "a\tb\r", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StringWidth(tt.input); got != tt.expected {
+				t.Errorf("Expected width %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}